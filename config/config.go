@@ -17,6 +17,7 @@ type Config struct {
 	HTTP     *HTTPConfig     `yaml:"http"`
 	Storage  *maputil.Map    `yaml:"storage"`
 	Cache    *CacheConfig    `yaml:"cache"`
+	Series   *SeriesConfig   `yaml:"series"`
 	Defaults *DefaultsConfig `yaml:"defaults"`
 }
 
@@ -27,6 +28,7 @@ func New(path string) (*Config, error) {
 		HTTP:     newHTTPConfig(),
 		Storage:  newStorageConfig(),
 		Cache:    newCacheConfig(),
+		Series:   newSeriesConfig(),
 		Defaults: newDefaultsConfig(),
 	}
 
@@ -50,6 +52,10 @@ func New(path string) (*Config, error) {
 	// Normalize settings and check for their validity
 	config.HTTP.BasePath = strings.TrimSuffix(config.HTTP.BasePath, "/")
 
+	if config.Series.MaxParallelQueries < 1 {
+		return nil, fmt.Errorf("invalid series max parallel queries value %d", config.Series.MaxParallelQueries)
+	}
+
 	if !timerange.IsValid(config.Defaults.TimeRange) {
 		return nil, fmt.Errorf("invalid default time range %q", config.Defaults.TimeRange)
 	}
@@ -0,0 +1,14 @@
+package config
+
+// SeriesConfig represents a series points retrieval configuration instance.
+type SeriesConfig struct {
+	MaxParallelQueries int `yaml:"max_parallel_queries"`
+	ProviderTimeout    int `yaml:"provider_timeout"`
+}
+
+func newSeriesConfig() *SeriesConfig {
+	return &SeriesConfig{
+		MaxParallelQueries: 8,
+		ProviderTimeout:    30,
+	}
+}
@@ -1,6 +1,7 @@
 package connector
 
 import (
+	"context"
 	"sort"
 
 	"facette.io/facette/catalog"
@@ -14,10 +15,13 @@ const defaultTimeout = 10
 var connectors = make(map[string]func(string, *maputil.Map, *logger.Logger) (Connector, error))
 
 // Connector represents a connector handler interface.
+//
+// The context passed to Points and Refresh methods is canceled when the result is no longer needed (e.g. client
+// request completion or poller worker shutdown), connectors being expected to abort pending operations accordingly.
 type Connector interface {
 	Name() string
-	Points(*series.Query) ([]series.Series, error)
-	Refresh(chan<- *catalog.Record) error
+	Points(context.Context, *series.Query) ([]series.Series, error)
+	Refresh(context.Context, chan<- *catalog.Record) error
 }
 
 // New creates a new instance of a connector handler.
@@ -0,0 +1,36 @@
+package connector
+
+import (
+	"testing"
+
+	"facette.io/facette/series"
+	"facette.io/logger"
+	"facette.io/maputil"
+)
+
+func testConnector(t *testing.T, typ string, settings *maputil.Map) Connector {
+	logger, _ := logger.NewLogger()
+
+	c, err := New(typ, typ, settings, logger)
+	if err != nil {
+		t.Fatalf("failed to initialize connector: %s", err)
+	}
+
+	return c
+}
+
+func compareSeries(expected, actual series.Series) bool {
+	if len(actual.Points) != len(expected.Points) {
+		return false
+	}
+
+	for i := range expected.Points {
+		if actual.Points[i].Value.IsNaN() != expected.Points[i].Value.IsNaN() ||
+			!actual.Points[i].Value.IsNaN() && actual.Points[i].Value != expected.Points[i].Value ||
+			!actual.Points[i].Time.Equal(expected.Points[i].Time) {
+			return false
+		}
+	}
+
+	return true
+}
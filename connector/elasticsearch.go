@@ -0,0 +1,499 @@
+// +build !disable_connector_elasticsearch
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/facette/set"
+	"facette.io/facette/version"
+	"facette.io/httputil"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/pkg/errors"
+)
+
+const (
+	elasticsearchDefaultAggregation    = "avg"
+	elasticsearchDefaultTermsSize      = 1000
+	elasticsearchDefaultTimestampField = "@timestamp"
+	elasticsearchURLMapping            = "/_mapping"
+	elasticsearchURLMultiSearch        = "/_msearch"
+	elasticsearchURLSearch             = "/_search"
+)
+
+var (
+	elasticsearchAggregations = set.New(
+		"avg",
+		"max",
+		"min",
+		"sum",
+	)
+
+	elasticsearchNumericTypes = set.New(
+		"byte",
+		"double",
+		"float",
+		"half_float",
+		"integer",
+		"long",
+		"scaled_float",
+		"short",
+		"unsigned_long",
+	)
+)
+
+func init() {
+	connectors["elasticsearch"] = func(name string, settings *maputil.Map, logger *logger.Logger) (Connector, error) {
+		var err error
+
+		c := &elasticsearchConnector{
+			name:   name,
+			logger: logger,
+		}
+
+		// Get connector handler settings
+		c.url, err = settings.GetString("url", "")
+		if err != nil {
+			return nil, err
+		} else if c.url == "" {
+			return nil, ErrMissingConnectorSetting("url")
+		}
+		c.url = normalizeURL(c.url)
+
+		c.index, err = settings.GetString("index", "")
+		if err != nil {
+			return nil, err
+		} else if c.index == "" {
+			return nil, ErrMissingConnectorSetting("index")
+		}
+
+		c.timestampField, err = settings.GetString("timestamp_field", elasticsearchDefaultTimestampField)
+		if err != nil {
+			return nil, err
+		}
+
+		c.originField, err = settings.GetString("origin_field", "")
+		if err != nil {
+			return nil, err
+		}
+
+		c.sourceField, err = settings.GetString("source_field", "")
+		if err != nil {
+			return nil, err
+		} else if c.sourceField == "" {
+			return nil, ErrMissingConnectorSetting("source_field")
+		}
+
+		c.metricFields, err = settings.GetStringSlice("metric_fields", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		c.aggregation, err = settings.GetString("aggregation", elasticsearchDefaultAggregation)
+		if err != nil {
+			return nil, err
+		} else if !elasticsearchAggregations.Has(c.aggregation) {
+			return nil, fmt.Errorf("unsupported %q aggregation", c.aggregation)
+		}
+
+		c.termsSize, err = settings.GetInt("terms_size", elasticsearchDefaultTermsSize)
+		if err != nil {
+			return nil, err
+		}
+
+		c.username, err = settings.GetString("username", "")
+		if err != nil {
+			return nil, err
+		}
+
+		c.password, err = settings.GetString("password", "")
+		if err != nil {
+			return nil, err
+		}
+
+		c.timeout, err = settings.GetInt("timeout", defaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		c.allowInsecure, err = settings.GetBool("allow_insecure_tls", false)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check remote instance URL
+		_, err = url.Parse(c.url)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse URL: %s", err)
+		}
+
+		c.client = httputil.NewClient(time.Duration(c.timeout)*time.Second, true, c.allowInsecure)
+
+		return c, nil
+	}
+}
+
+type elasticsearchConnector struct {
+	name           string
+	url            string
+	index          string
+	timestampField string
+	originField    string
+	sourceField    string
+	metricFields   []string
+	aggregation    string
+	termsSize      int
+	username       string
+	password       string
+	timeout        int
+	allowInsecure  bool
+	client         *http.Client
+	logger         *logger.Logger
+}
+
+func (c *elasticsearchConnector) Name() string {
+	return c.name
+}
+
+func (c *elasticsearchConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("requested metrics list is empty")
+	}
+
+	step := query.EndTime.Sub(query.StartTime) / time.Duration(query.Sample)
+	interval := step.Nanoseconds() / 1000000
+	if interval < 1000 {
+		interval = 1000
+	}
+
+	// Prepare multi-search request body, one search per metric
+	body := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(body)
+
+	for _, m := range query.Metrics {
+		field, err := m.Attributes.GetString("field", "")
+		if err != nil || field == "" {
+			return nil, errors.Wrap(ErrInvalidAttribute, "field")
+		}
+
+		source, err := m.Attributes.GetString("source", "")
+		if err != nil || source == "" {
+			return nil, errors.Wrap(ErrInvalidAttribute, "source")
+		}
+
+		filters := []interface{}{
+			map[string]interface{}{"range": map[string]interface{}{
+				c.timestampField: map[string]interface{}{
+					"gte":    query.StartTime.UnixNano() / 1000000,
+					"lte":    query.EndTime.UnixNano() / 1000000,
+					"format": "epoch_millis",
+				},
+			}},
+			map[string]interface{}{"term": map[string]interface{}{c.sourceField: source}},
+		}
+
+		if c.originField != "" {
+			origin, err := m.Attributes.GetString("origin", "")
+			if err != nil || origin == "" {
+				return nil, errors.Wrap(ErrInvalidAttribute, "origin")
+			}
+
+			filters = append(filters, map[string]interface{}{"term": map[string]interface{}{c.originField: origin}})
+		}
+
+		if err := enc.Encode(map[string]interface{}{"index": c.index}); err != nil {
+			return nil, fmt.Errorf("unable to marshal search request: %s", err)
+		}
+
+		err = enc.Encode(map[string]interface{}{
+			"size": 0,
+			"query": map[string]interface{}{
+				"bool": map[string]interface{}{"filter": filters},
+			},
+			"aggs": map[string]interface{}{
+				"histogram": map[string]interface{}{
+					"date_histogram": map[string]interface{}{
+						"field":          c.timestampField,
+						"fixed_interval": fmt.Sprintf("%dms", interval),
+						"min_doc_count":  0,
+						"extended_bounds": map[string]interface{}{
+							"min": query.StartTime.UnixNano() / 1000000,
+							"max": query.EndTime.UnixNano() / 1000000,
+						},
+					},
+					"aggs": map[string]interface{}{
+						"value": map[string]interface{}{
+							c.aggregation: map[string]interface{}{"field": field},
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal search request: %s", err)
+		}
+	}
+
+	mr := elasticsearchMultiSearchResponse{}
+	if err := c.do(ctx, "POST", elasticsearchURLMultiSearch, "application/x-ndjson", body, &mr); err != nil {
+		return nil, err
+	} else if len(mr.Responses) != len(query.Metrics) {
+		return nil, fmt.Errorf("expected %d responses but got %d", len(query.Metrics), len(mr.Responses))
+	}
+
+	result := make([]series.Series, len(query.Metrics))
+	for i, r := range mr.Responses {
+		if r.Error != nil {
+			return nil, fmt.Errorf("failed to fetch points: %s", r.Error.Reason)
+		}
+
+		for _, b := range r.Aggregations.Histogram.Buckets {
+			value := series.Value(math.NaN())
+			if b.Value.Value != nil {
+				value = series.Value(*b.Value.Value)
+			}
+
+			result[i].Points = append(result[i].Points, series.Point{
+				Time:  time.Unix(0, b.Key*int64(time.Millisecond)),
+				Value: value,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (c *elasticsearchConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
+	fields := c.metricFields
+
+	// Discover numeric fields from indices mapping if none provided
+	if len(fields) == 0 {
+		mr := map[string]elasticsearchMappingResponse{}
+		if err := c.do(ctx, "GET", "/"+url.PathEscape(c.index)+elasticsearchURLMapping, "", nil, &mr); err != nil {
+			return fmt.Errorf("failed to fetch mapping: %s", err)
+		}
+
+		fieldsSet := set.New()
+		for _, index := range mr {
+			elasticsearchNumericFields(index.Mappings.Properties, "", fieldsSet)
+		}
+
+		fields = set.StringSlice(fieldsSet)
+		sort.Strings(fields)
+	}
+
+	// Prepare terms aggregation request, counting values of each field per source
+	fieldsAggs := map[string]interface{}{}
+	for i, field := range fields {
+		fieldsAggs[fmt.Sprintf("field%d", i)] = map[string]interface{}{
+			"value_count": map[string]interface{}{"field": field},
+		}
+	}
+
+	aggs := map[string]interface{}{
+		"sources": map[string]interface{}{
+			"terms": map[string]interface{}{"field": c.sourceField, "size": c.termsSize},
+			"aggs":  fieldsAggs,
+		},
+	}
+
+	if c.originField != "" {
+		aggs = map[string]interface{}{
+			"origins": map[string]interface{}{
+				"terms": map[string]interface{}{"field": c.originField, "size": c.termsSize},
+				"aggs":  aggs,
+			},
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"size": 0, "aggs": aggs})
+	if err != nil {
+		return fmt.Errorf("unable to marshal search request: %s", err)
+	}
+
+	sr := elasticsearchTermsResponse{}
+	err = c.do(ctx, "POST", "/"+url.PathEscape(c.index)+elasticsearchURLSearch, "application/json", bytes.NewReader(body),
+		&sr)
+	if err != nil {
+		return fmt.Errorf("failed to fetch terms: %s", err)
+	}
+
+	emit := func(origin string, sources []elasticsearchTermsBucket) {
+		for _, source := range sources {
+			for i, field := range fields {
+				if count, ok := source.Fields[fmt.Sprintf("field%d", i)]; !ok || count.Value == nil || *count.Value == 0 {
+					continue
+				}
+
+				attrs := &maputil.Map{
+					"source": fmt.Sprintf("%v", source.Key),
+					"field":  field,
+				}
+
+				// Always keep origin when mapped from a field, as it is used to filter documents when fetching points
+				if c.originField != "" {
+					attrs.Set("origin", origin)
+				}
+
+				output <- &catalog.Record{
+					Origin:     origin,
+					Source:     fmt.Sprintf("%v", source.Key),
+					Metric:     field,
+					Attributes: attrs,
+				}
+			}
+		}
+	}
+
+	if c.originField != "" {
+		for _, origin := range sr.Aggregations.Origins.Buckets {
+			emit(fmt.Sprintf("%v", origin.Key), origin.Sources.Buckets)
+		}
+	} else {
+		emit(c.name, sr.Aggregations.Sources.Buckets)
+	}
+
+	return nil
+}
+
+func (c *elasticsearchConnector) do(ctx context.Context, method, path, contentType string, body io.Reader,
+	out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return fmt.Errorf("unable to set up HTTP request: %s", err)
+	}
+	req.Header.Add("User-Agent", "facette/"+version.Version)
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to perform HTTP request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		er := elasticsearchErrorResponse{}
+		if err := httputil.BindJSON(resp, &er); err != nil || er.Error == nil {
+			return fmt.Errorf("unexpected HTTP status code %d", resp.StatusCode)
+		}
+
+		return fmt.Errorf("%s", er.Error.Reason)
+	}
+
+	if err := httputil.BindJSON(resp, out); err != nil {
+		return fmt.Errorf("unable to unmarshal JSON data: %s", err)
+	}
+
+	return nil
+}
+
+func elasticsearchNumericFields(properties map[string]elasticsearchMappingProperty, prefix string, fields *set.Set) {
+	for name, p := range properties {
+		if p.Properties != nil {
+			elasticsearchNumericFields(p.Properties, prefix+name+".", fields)
+		} else if elasticsearchNumericTypes.Has(p.Type) {
+			fields.Add(prefix + name)
+		}
+	}
+}
+
+type elasticsearchError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+type elasticsearchErrorResponse struct {
+	Error *elasticsearchError `json:"error"`
+}
+
+type elasticsearchMappingResponse struct {
+	Mappings struct {
+		Properties map[string]elasticsearchMappingProperty `json:"properties"`
+	} `json:"mappings"`
+}
+
+type elasticsearchMappingProperty struct {
+	Type       string                                  `json:"type"`
+	Properties map[string]elasticsearchMappingProperty `json:"properties"`
+}
+
+type elasticsearchValue struct {
+	Value *float64 `json:"value"`
+}
+
+type elasticsearchTermsBucket struct {
+	Key     interface{} `json:"key"`
+	Sources struct {
+		Buckets []elasticsearchTermsBucket `json:"buckets"`
+	} `json:"sources"`
+	Fields map[string]elasticsearchValue `json:"-"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *elasticsearchTermsBucket) UnmarshalJSON(data []byte) error {
+	type bucket elasticsearchTermsBucket
+
+	if err := json.Unmarshal(data, (*bucket)(b)); err != nil {
+		return err
+	}
+
+	// Retrieve fields values count sub-aggregations
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	b.Fields = make(map[string]elasticsearchValue)
+	for key, value := range raw {
+		v := elasticsearchValue{}
+		if json.Unmarshal(value, &v) == nil && v.Value != nil {
+			b.Fields[key] = v
+		}
+	}
+
+	return nil
+}
+
+type elasticsearchTermsResponse struct {
+	Aggregations struct {
+		Origins struct {
+			Buckets []elasticsearchTermsBucket `json:"buckets"`
+		} `json:"origins"`
+		Sources struct {
+			Buckets []elasticsearchTermsBucket `json:"buckets"`
+		} `json:"sources"`
+	} `json:"aggregations"`
+}
+
+type elasticsearchMultiSearchResponse struct {
+	Responses []struct {
+		Error        *elasticsearchError `json:"error"`
+		Aggregations struct {
+			Histogram struct {
+				Buckets []struct {
+					Key   int64              `json:"key"`
+					Value elasticsearchValue `json:"value"`
+				} `json:"buckets"`
+			} `json:"histogram"`
+		} `json:"aggregations"`
+	} `json:"responses"`
+}
@@ -0,0 +1,280 @@
+// +build !disable_connector_elasticsearch
+
+package connector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/maputil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testElasticsearchMapping = `{
+  "metrics-2017.07.14": {
+    "mappings": {
+      "properties": {
+        "@timestamp": {"type": "date"},
+        "host": {"properties": {"name": {"type": "keyword"}}},
+        "system": {
+          "properties": {
+            "load": {"properties": {"1": {"type": "float"}, "5": {"type": "float"}}},
+            "os": {"type": "keyword"}
+          }
+        }
+      }
+    }
+  }
+}`
+
+	testElasticsearchTerms = `{
+  "took": 3,
+  "timed_out": false,
+  "hits": {"total": {"value": 120, "relation": "eq"}, "hits": []},
+  "aggregations": {
+    "sources": {
+      "buckets": [
+        {"key": "web1", "doc_count": 80, "field0": {"value": 80}, "field1": {"value": 80}},
+        {"key": "web2", "doc_count": 40, "field0": {"value": 40}, "field1": {"value": 0}}
+      ]
+    }
+  }
+}`
+
+	testElasticsearchOriginTerms = `{
+  "took": 3,
+  "timed_out": false,
+  "hits": {"total": {"value": 120, "relation": "eq"}, "hits": []},
+  "aggregations": {
+    "origins": {
+      "buckets": [
+        {
+          "key": "elasticsearch",
+          "doc_count": 80,
+          "sources": {"buckets": [{"key": "web1", "doc_count": 80, "field0": {"value": 80}, "field1": {"value": 0}}]}
+        },
+        {
+          "key": "dc2",
+          "doc_count": 40,
+          "sources": {"buckets": [{"key": "web2", "doc_count": 40, "field0": {"value": 40}, "field1": {"value": 0}}]}
+        }
+      ]
+    }
+  }
+}`
+
+	testElasticsearchMultiSearch = `{
+  "took": 5,
+  "responses": [
+    {
+      "aggregations": {
+        "histogram": {
+          "buckets": [
+            {"key_as_string": "2017-07-14T02:40:00.000Z", "key": 1500000000000, "doc_count": 2, "value": {"value": 0.5}},
+            {"key_as_string": "2017-07-14T02:41:00.000Z", "key": 1500000060000, "doc_count": 0, "value": {"value": null}}
+          ]
+        }
+      },
+      "status": 200
+    },
+    {
+      "aggregations": {
+        "histogram": {
+          "buckets": [
+            {"key_as_string": "2017-07-14T02:40:00.000Z", "key": 1500000000000, "doc_count": 1, "value": {"value": 1.25}}
+          ]
+        }
+      },
+      "status": 200
+    }
+  ]
+}`
+)
+
+func Test_Elasticsearch_Refresh(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/metrics-*" + elasticsearchURLMapping:
+			rw.Write([]byte(testElasticsearchMapping))
+
+		case "/metrics-*" + elasticsearchURLSearch:
+			req := map[string]interface{}{}
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, map[string]interface{}{"field": "host.name", "size": float64(1000)},
+				req["aggs"].(map[string]interface{})["sources"].(map[string]interface{})["terms"])
+
+			rw.Write([]byte(testElasticsearchTerms))
+
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := testConnector(t, "elasticsearch", &maputil.Map{
+		"url":          ts.URL,
+		"index":        "metrics-*",
+		"source_field": "host.name",
+	})
+
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 3)
+	assert.Equal(t, catalog.Record{
+		Origin:     "elasticsearch",
+		Source:     "web1",
+		Metric:     "system.load.1",
+		Attributes: &maputil.Map{"source": "web1", "field": "system.load.1"},
+	}, *records[0])
+	assert.Equal(t, "web1", records[1].Source)
+	assert.Equal(t, "system.load.5", records[1].Metric)
+	assert.Equal(t, "web2", records[2].Source)
+	assert.Equal(t, "system.load.1", records[2].Metric)
+}
+
+func Test_Elasticsearch_Refresh_OriginField(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/metrics-*" + elasticsearchURLMapping:
+			rw.Write([]byte(testElasticsearchMapping))
+
+		case "/metrics-*" + elasticsearchURLSearch:
+			rw.Write([]byte(testElasticsearchOriginTerms))
+
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := testConnector(t, "elasticsearch", &maputil.Map{
+		"url":          ts.URL,
+		"index":        "metrics-*",
+		"source_field": "host.name",
+		"origin_field": "datacenter",
+	})
+
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	// Origin attribute must be set even if matching the connector name
+	assert.Len(t, records, 2)
+	assert.Equal(t, catalog.Record{
+		Origin:     "elasticsearch",
+		Source:     "web1",
+		Metric:     "system.load.1",
+		Attributes: &maputil.Map{"origin": "elasticsearch", "source": "web1", "field": "system.load.1"},
+	}, *records[0])
+	assert.Equal(t, catalog.Record{
+		Origin:     "dc2",
+		Source:     "web2",
+		Metric:     "system.load.1",
+		Attributes: &maputil.Map{"origin": "dc2", "source": "web2", "field": "system.load.1"},
+	}, *records[1])
+}
+
+func Test_Elasticsearch_Points(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != elasticsearchURLMultiSearch {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+
+		lines := []map[string]interface{}{}
+
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			line := map[string]interface{}{}
+			assert.Nil(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+
+		assert.Len(t, lines, 4)
+		assert.Equal(t, map[string]interface{}{"index": "metrics-*"}, lines[0])
+
+		histogram := lines[1]["aggs"].(map[string]interface{})["histogram"].(map[string]interface{})
+		assert.Equal(t, "60000ms", histogram["date_histogram"].(map[string]interface{})["fixed_interval"])
+		assert.Equal(t, map[string]interface{}{"avg": map[string]interface{}{"field": "system.load.1"}},
+			histogram["aggs"].(map[string]interface{})["value"])
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(testElasticsearchMultiSearch))
+	}))
+	defer ts.Close()
+
+	c := testConnector(t, "elasticsearch", &maputil.Map{
+		"url":          ts.URL,
+		"index":        "metrics-*",
+		"source_field": "host.name",
+	})
+
+	cat := catalog.New("elasticsearch", c)
+	for _, r := range []*catalog.Record{
+		{Origin: "elasticsearch", Source: "web1", Metric: "system.load.1", Attributes: &maputil.Map{
+			"source": "web1", "field": "system.load.1",
+		}},
+		{Origin: "elasticsearch", Source: "web2", Metric: "system.load.1", Attributes: &maputil.Map{
+			"source": "web2", "field": "system.load.1",
+		}},
+	} {
+		assert.Nil(t, cat.Insert(r))
+	}
+
+	m1, _ := cat.Metric("elasticsearch", "web1", "system.load.1")
+	m2, _ := cat.Metric("elasticsearch", "web2", "system.load.1")
+
+	startTime := time.Unix(1500000000, 0)
+
+	result, err := c.Points(context.Background(), &series.Query{
+		StartTime: startTime,
+		EndTime:   startTime.Add(10 * time.Minute),
+		Sample:    10,
+		Metrics:   []*catalog.Metric{m1, m2},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+
+	expected := series.Series{Points: []series.Point{
+		{Time: startTime, Value: 0.5},
+		{Time: startTime.Add(time.Minute), Value: series.Value(math.NaN())},
+	}}
+	if !compareSeries(expected, result[0]) {
+		t.Errorf("Not equal: \nexpected: %#v\nactual  : %#v", expected, result[0])
+	}
+
+	assert.Equal(t, []series.Point{{Time: startTime, Value: 1.25}}, result[1].Points)
+}
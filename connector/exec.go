@@ -0,0 +1,345 @@
+// +build !disable_connector_exec
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os/exec"
+	"strings"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	connectors["exec"] = func(name string, settings *maputil.Map, logger *logger.Logger) (Connector, error) {
+		var err error
+
+		c := &execConnector{
+			name:   name,
+			logger: logger,
+		}
+
+		// Get connector handler settings
+		c.command, err = settings.GetString("command", "")
+		if err != nil {
+			return nil, err
+		} else if c.command == "" {
+			return nil, ErrMissingConnectorSetting("command")
+		}
+
+		c.args, err = settings.GetStringSlice("args", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		c.options, err = settings.GetMap("options", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		c.timeout, err = settings.GetInt("timeout", defaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check for command availability
+		c.command, err = exec.LookPath(c.command)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find command: %s", err)
+		}
+
+		return c, nil
+	}
+
+	// Attributes are defined by plugins thus may contain any JSON value
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// execConnector represents an external plugin connector instance.
+type execConnector struct {
+	name    string
+	command string
+	args    []string
+	options maputil.Map
+	timeout int
+	logger  *logger.Logger
+}
+
+func (c *execConnector) Name() string {
+	return c.name
+}
+
+func (c *execConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("requested metrics list is empty")
+	}
+
+	q := &execQuery{
+		StartTime: query.StartTime.Unix(),
+		EndTime:   query.EndTime.Unix(),
+		Sample:    query.Sample,
+		Metrics:   make([]execRecord, len(query.Metrics)),
+	}
+
+	// Send back names originally emitted by the plugin, as catalog names may have been rewritten by filters
+	for i, m := range query.Metrics {
+		names := make([]string, 3)
+		for j, key := range []string{"origin", "source", "metric"} {
+			v, err := m.Attributes.GetString(key, "")
+			if err != nil || v == "" {
+				return nil, errors.Wrap(ErrInvalidAttribute, key)
+			}
+			names[j] = v
+		}
+
+		attrs, err := m.Attributes.GetMap("attributes", maputil.Map{})
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidAttribute, "attributes")
+		}
+
+		q.Metrics[i] = execRecord{
+			Origin:     names[0],
+			Source:     names[1],
+			Metric:     names[2],
+			Attributes: &attrs,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.timeout)*time.Second)
+	defer cancel()
+
+	result := []series.Series{}
+
+	err := c.run(ctx, &execRequest{Method: "points", Query: q}, func(msg *execMessage) error {
+		if msg.Series == nil {
+			return fmt.Errorf("unexpected message")
+		} else if len(result) > 0 {
+			return fmt.Errorf("unexpected duplicate series message")
+		} else if len(msg.Series) != len(query.Metrics) {
+			return fmt.Errorf("expected %d series but got %d", len(query.Metrics), len(msg.Series))
+		}
+
+		for _, s := range msg.Series {
+			points := make([]series.Point, len(s.Points))
+			for i, p := range s.Points {
+				points[i] = series.Point{Time: time.Unix(p.Time, 0), Value: series.Value(math.NaN())}
+				if p.Value != nil {
+					points[i].Value = series.Value(*p.Value)
+				}
+			}
+
+			result = append(result, series.Series{Points: points})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	} else if len(result) != len(query.Metrics) {
+		return nil, fmt.Errorf("plugin returned no series")
+	}
+
+	return result, nil
+}
+
+func (c *execConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
+	return c.run(ctx, &execRequest{Method: "refresh"}, func(msg *execMessage) error {
+		if msg.Record == nil {
+			return fmt.Errorf("unexpected message")
+		} else if msg.Record.Source == "" || msg.Record.Metric == "" {
+			c.logger.Warning("skipping record with missing source or metric name")
+			return nil
+		}
+
+		if msg.Record.Origin == "" {
+			msg.Record.Origin = c.name
+		}
+
+		attrs := map[string]interface{}{}
+		if msg.Record.Attributes != nil {
+			attrs = *msg.Record.Attributes
+		}
+
+		// Keep original names along with plugin attributes, to be sent back as is with points queries
+		output <- &catalog.Record{
+			Origin: msg.Record.Origin,
+			Source: msg.Record.Source,
+			Metric: msg.Record.Metric,
+			Attributes: &maputil.Map{
+				"origin":     msg.Record.Origin,
+				"source":     msg.Record.Source,
+				"metric":     msg.Record.Metric,
+				"attributes": attrs,
+			},
+		}
+
+		return nil
+	})
+}
+
+// run executes the plugin command, sends it the request and calls the handler function for each message read from
+// its output.
+func (c *execConnector) run(ctx context.Context, req *execRequest, handler func(*execMessage) error) error {
+	req.Name = c.name
+	req.Options = c.options
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("unable to marshal request: %s", err)
+	}
+
+	cmd := exec.CommandContext(ctx, c.command, c.args...)
+	cmd.Stdin = bytes.NewReader(input)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("unable to set up plugin output: %s", err)
+	}
+
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("unable to set up plugin output: %s", err)
+	}
+
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("unable to start plugin: %s", err)
+	}
+
+	stderr := bytes.NewBuffer(nil)
+	stderrDone := make(chan struct{})
+
+	go func() {
+		io.Copy(stderr, stderrPipe)
+		close(stderrDone)
+	}()
+
+	// Close plugin output once context is done, as the killed process children might still hold it open
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			stdout.Close()
+		case <-done:
+		}
+	}()
+
+	dec := json.NewDecoder(stdout)
+	for {
+		msg := &execMessage{}
+		if err = dec.Decode(msg); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = fmt.Errorf("unable to unmarshal plugin message: %s", err)
+			break
+		} else if msg.Error != "" {
+			err = fmt.Errorf("plugin error: %s", msg.Error)
+			break
+		}
+
+		if err = handler(msg); err != nil {
+			err = fmt.Errorf("plugin error: %s", err)
+			break
+		}
+	}
+
+	if err != nil {
+		// Drain remaining output, preventing the plugin from blocking on write
+		io.Copy(ioutil.Discard, stdout)
+	}
+
+	// Wait for error output to be fully read unless context is done, as the killed process children might still hold
+	// it open
+	select {
+	case <-stderrDone:
+	case <-ctx.Done():
+	}
+
+	werr := cmd.Wait()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("plugin timed out")
+	} else if ctx.Err() != nil {
+		err = ctx.Err()
+	} else if werr != nil && err == nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("plugin failed: %s: %s", werr, msg)
+		} else {
+			err = fmt.Errorf("plugin failed: %s", werr)
+		}
+	}
+
+	return err
+}
+
+// execRequest represents a request sent to an external plugin.
+type execRequest struct {
+	Method  string      `json:"method"`
+	Name    string      `json:"name"`
+	Options maputil.Map `json:"options,omitempty"`
+	Query   *execQuery  `json:"query,omitempty"`
+}
+
+// execQuery represents a points query sent to an external plugin.
+type execQuery struct {
+	StartTime int64        `json:"start_time"`
+	EndTime   int64        `json:"end_time"`
+	Sample    int          `json:"sample"`
+	Metrics   []execRecord `json:"metrics"`
+}
+
+// execRecord represents a catalog record exchanged with an external plugin.
+type execRecord struct {
+	Origin     string       `json:"origin"`
+	Source     string       `json:"source"`
+	Metric     string       `json:"metric"`
+	Attributes *maputil.Map `json:"attributes,omitempty"`
+}
+
+// execMessage represents a message read from an external plugin output.
+type execMessage struct {
+	Record *execRecord  `json:"record"`
+	Series []execSeries `json:"series"`
+	Error  string       `json:"error"`
+}
+
+// execSeries represents a series returned by an external plugin.
+type execSeries struct {
+	Points []execPoint `json:"points"`
+}
+
+// execPoint represents a point returned by an external plugin, null values being accepted.
+type execPoint struct {
+	Time  int64
+	Value *float64
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *execPoint) UnmarshalJSON(data []byte) error {
+	input := [2]*float64{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	} else if input[0] == nil {
+		return fmt.Errorf("missing point time")
+	}
+
+	p.Time = int64(*input[0])
+	p.Value = input[1]
+
+	return nil
+}
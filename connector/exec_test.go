@@ -0,0 +1,175 @@
+// +build !disable_connector_exec
+
+package connector
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Exec(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found, skipping reference plugin test")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "facette")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Build reference plugin
+	plugin := filepath.Join(tmpDir, "exec-plugin")
+
+	out, err := exec.Command(goBin, "build", "-o", plugin, "../misc/exec-plugin").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build reference plugin: %s: %s", err, out)
+	}
+
+	logger, _ := logger.NewLogger()
+
+	c, err := New("exec", "waves", &maputil.Map{
+		"command": plugin,
+		"options": map[string]interface{}{"sources": []interface{}{"host1"}, "period": 240},
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to initialize connector: %s", err)
+	}
+
+	// Check catalog records
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, catalog.Record{Origin: "waves", Source: "host1", Metric: "wave.sine", Attributes: &maputil.Map{
+		"origin":     "waves",
+		"source":     "host1",
+		"metric":     "wave.sine",
+		"attributes": map[string]interface{}{"period": float64(240)},
+	}}, *records[0])
+	assert.Equal(t, "wave.square", records[1].Metric)
+
+	// Check points, catalog names being rewritten as filters would do
+	cat := catalog.New("waves", c)
+	for _, r := range records {
+		r.Metric = strings.Replace(r.Metric, "wave.", "rewritten.", 1)
+		assert.Nil(t, cat.Insert(r))
+	}
+
+	m1, _ := cat.Metric("waves", "host1", "rewritten.sine")
+	m2, _ := cat.Metric("waves", "host1", "rewritten.square")
+
+	startTime := time.Unix(1500000000, 0)
+
+	result, err := c.Points(context.Background(), &series.Query{
+		StartTime: startTime,
+		EndTime:   startTime.Add(4 * time.Minute),
+		Sample:    4,
+		Metrics:   []*catalog.Metric{m1, m2},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Len(t, result[0].Points, 5)
+
+	for i, p := range result[0].Points {
+		assert.Equal(t, startTime.Add(time.Duration(i)*time.Minute), p.Time)
+		assert.InDelta(t, math.Sin(float64(i)*math.Pi/2), float64(p.Value), 1e-6)
+	}
+
+	assert.Equal(t, series.Value(1), result[1].Points[1].Value)
+	assert.Equal(t, series.Value(-1), result[1].Points[3].Value)
+}
+
+func Test_Exec_Failure(t *testing.T) {
+	logger, _ := logger.NewLogger()
+
+	for _, script := range []string{
+		"echo 'something went wrong' >&2; exit 1",
+		`echo '{"error": "something went wrong"}'`,
+	} {
+		c, err := New("exec", "exec", &maputil.Map{
+			"command": "sh",
+			"args":    []string{"-c", script},
+		}, logger)
+		if err != nil {
+			t.Fatalf("failed to initialize connector: %s", err)
+		}
+
+		err = c.Refresh(context.Background(), make(chan *catalog.Record))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "something went wrong")
+	}
+}
+
+func Test_Exec_DuplicateSeries(t *testing.T) {
+	logger, _ := logger.NewLogger()
+
+	c, err := New("exec", "exec", &maputil.Map{
+		"command": "sh",
+		"args": []string{"-c", `echo '{"series": [{"points": [[1500000000, 1]]}]}'; ` +
+			`echo '{"series": [{"points": [[1500000000, 2]]}]}'`},
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to initialize connector: %s", err)
+	}
+
+	cat := catalog.New("exec", c)
+	assert.Nil(t, cat.Insert(&catalog.Record{Origin: "exec", Source: "host1", Metric: "load", Attributes: &maputil.Map{
+		"origin": "exec", "source": "host1", "metric": "load",
+	}}))
+
+	m, _ := cat.Metric("exec", "host1", "load")
+
+	_, err = c.Points(context.Background(), &series.Query{
+		StartTime: time.Unix(1500000000, 0),
+		EndTime:   time.Unix(1500000060, 0),
+		Sample:    1,
+		Metrics:   []*catalog.Metric{m},
+	})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unexpected duplicate series message")
+}
+
+func Test_Exec_Cancel(t *testing.T) {
+	logger, _ := logger.NewLogger()
+
+	c, err := New("exec", "exec", &maputil.Map{
+		"command": "sh",
+		"args":    []string{"-c", "sleep 10"},
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to initialize connector: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	err = c.Refresh(ctx, make(chan *catalog.Record))
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
@@ -4,6 +4,7 @@ package connector
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -75,7 +76,7 @@ func (c *facetteConnector) Name() string {
 	return c.name
 }
 
-func (c *facetteConnector) Points(query *series.Query) ([]series.Series, error) {
+func (c *facetteConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
 	// Convert query into a Facette point request
 	body, err := json.Marshal(series.Request{
 		StartTime: query.StartTime,
@@ -109,7 +110,7 @@ func (c *facetteConnector) Points(query *series.Query) ([]series.Series, error)
 	}
 
 	// Create new HTTP request
-	req, err := http.NewRequest("POST", c.url+facetteURLPoints, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url+facetteURLPoints, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("unable to set up HTTP request: %s", err)
 	}
@@ -140,9 +141,9 @@ func (c *facetteConnector) Points(query *series.Query) ([]series.Series, error)
 	return result, nil
 }
 
-func (c *facetteConnector) Refresh(output chan<- *catalog.Record) error {
+func (c *facetteConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
 	// Create new HTTP request
-	req, err := http.NewRequest("GET", c.url+facetteURLCatalog, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url+facetteURLCatalog, nil)
 	if err != nil {
 		return fmt.Errorf("unable to set up HTTP request: %s", err)
 	}
@@ -3,6 +3,7 @@
 package connector
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -94,7 +95,7 @@ func (c *graphiteConnector) Name() string {
 	return c.name
 }
 
-func (c *graphiteConnector) Points(query *series.Query) ([]series.Series, error) {
+func (c *graphiteConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
 	var (
 		points  []graphitePoint
 		results []series.Series
@@ -110,7 +111,7 @@ func (c *graphiteConnector) Points(query *series.Query) ([]series.Series, error)
 	}
 
 	// Request data from back-end
-	req, err := http.NewRequest("GET", strings.TrimSuffix(c.url, "/")+graphiteURLRender+"?"+queryURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(c.url, "/")+graphiteURLRender+"?"+queryURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("unable to set up HTTP request: %s", err)
 	}
@@ -136,10 +137,10 @@ func (c *graphiteConnector) Points(query *series.Query) ([]series.Series, error)
 	return results, nil
 }
 
-func (c *graphiteConnector) Refresh(output chan<- *catalog.Record) error {
+func (c *graphiteConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
 	var series []string
 
-	req, err := http.NewRequest("GET", strings.TrimSuffix(c.url, "/")+graphiteURLMetrics, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(c.url, "/")+graphiteURLMetrics, nil)
 	if err != nil {
 		return fmt.Errorf("unable to set up HTTP request: %s", err)
 	}
@@ -3,6 +3,7 @@
 package connector
 
 import (
+	"context"
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
@@ -159,7 +160,7 @@ func (c *influxDBConnector) Name() string {
 	return c.name
 }
 
-func (c *influxDBConnector) Points(q *series.Query) ([]series.Series, error) {
+func (c *influxDBConnector) Points(ctx context.Context, q *series.Query) ([]series.Series, error) {
 	var queries []string
 
 	l := len(q.Metrics)
@@ -217,7 +218,7 @@ func (c *influxDBConnector) Points(q *series.Query) ([]series.Series, error) {
 	}
 
 	// Execute query
-	resp, err := c.client.Query(query)
+	resp, err := c.query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch points: %s", err)
 	} else if resp.Error() != nil {
@@ -254,7 +255,7 @@ func (c *influxDBConnector) Points(q *series.Query) ([]series.Series, error) {
 	return results, nil
 }
 
-func (c *influxDBConnector) Refresh(output chan<- *catalog.Record) error {
+func (c *influxDBConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
 	// Query back-end for sample rows (used to detect numerical values)
 	columnsMap := make(map[string][]string)
 
@@ -263,7 +264,7 @@ func (c *influxDBConnector) Refresh(output chan<- *catalog.Record) error {
 		Database: c.database,
 	}
 
-	resp, err := c.client.Query(q)
+	resp, err := c.query(ctx, q)
 	if err != nil {
 		return fmt.Errorf("failed to fetch sample rows: %s", err)
 	} else if resp.Error() != nil {
@@ -321,7 +322,7 @@ func (c *influxDBConnector) Refresh(output chan<- *catalog.Record) error {
 			Database: c.database,
 		}
 
-		resp, err = c.client.Query(q)
+		resp, err = c.query(ctx, q)
 		if err != nil {
 			return fmt.Errorf("failed to fetch series: %s", err)
 		} else if resp.Error() != nil {
@@ -387,19 +388,28 @@ func (c *influxDBConnector) Refresh(output chan<- *catalog.Record) error {
 	return nil
 }
 
-func mapKey(seriesColumns map[string]string, item string) (string, string) {
-	if item == "name" {
-		return "", seriesColumns["name"]
-	} else if strings.HasPrefix(item, "column:") {
-		// Try to match row column
-		name := strings.TrimPrefix(item, "column:")
-		if value, ok := seriesColumns[name]; ok {
-			return name, value
-		}
+// query executes an InfluxDB query, returning early if the context is done before the query completes (the client
+// library doesn't support cancellation).
+func (c *influxDBConnector) query(ctx context.Context, q influxdb.Query) (*influxdb.Response, error) {
+	type result struct {
+		resp *influxdb.Response
+		err  error
 	}
 
-	// Nothing matched
-	return "", ""
+	ch := make(chan result, 1)
+
+	go func() {
+		resp, err := c.client.Query(q)
+		ch <- result{resp, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case r := <-ch:
+		return r.resp, r.err
+	}
 }
 
 func mapSeriesColumns(series string) (map[string]string, error) {
@@ -412,9 +422,3 @@ func mapSeriesColumns(series string) (map[string]string, error) {
 
 	return columns, nil
 }
-
-type influxDBMapping struct {
-	Source []string
-	Metric []string
-	Glue   string
-}
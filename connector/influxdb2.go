@@ -0,0 +1,567 @@
+// +build !disable_connector_influxdb2
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/facette/set"
+	"facette.io/facette/version"
+	"facette.io/httputil"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/pkg/errors"
+)
+
+const (
+	influxDB2DefaultAggregate     = "mean"
+	influxDB2DefaultDiscoverRange = "-24h"
+	influxDB2URLQuery             = "/api/v2/query"
+)
+
+var (
+	influxDB2Aggregates = set.New(
+		"count",
+		"first",
+		"last",
+		"max",
+		"mean",
+		"median",
+		"min",
+		"sum",
+	)
+
+	influxDB2DurationRegexp = regexp.MustCompile(`^-(\d+(ns|us|ms|s|m|h|d|w|mo|y))+$`)
+
+	influxDB2StringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `${`, `\${`)
+)
+
+func init() {
+	connectors["influxdb2"] = func(name string, settings *maputil.Map, logger *logger.Logger) (Connector, error) {
+		var (
+			mapping maputil.Map
+			glue    string
+			err     error
+		)
+
+		c := &influxDB2Connector{
+			name: name,
+			mapping: &influxDBMapping{
+				Source: []string{"column:host"},
+				Metric: []string{"name"},
+				Glue:   ".",
+			},
+			logger: logger,
+		}
+
+		// Load provider configuration
+		c.url, err = settings.GetString("url", "")
+		if err != nil {
+			return nil, err
+		} else if c.url == "" {
+			return nil, ErrMissingConnectorSetting("url")
+		}
+		c.url = normalizeURL(c.url)
+
+		c.token, err = settings.GetString("token", "")
+		if err != nil {
+			return nil, err
+		} else if c.token == "" {
+			return nil, ErrMissingConnectorSetting("token")
+		}
+
+		c.org, err = settings.GetString("org", "")
+		if err != nil {
+			return nil, err
+		} else if c.org == "" {
+			return nil, ErrMissingConnectorSetting("org")
+		}
+
+		c.bucket, err = settings.GetString("bucket", "")
+		if err != nil {
+			return nil, err
+		} else if c.bucket == "" {
+			return nil, ErrMissingConnectorSetting("bucket")
+		}
+
+		c.aggregate, err = settings.GetString("aggregate", influxDB2DefaultAggregate)
+		if err != nil {
+			return nil, err
+		} else if !influxDB2Aggregates.Has(c.aggregate) {
+			return nil, fmt.Errorf("unsupported %q aggregate function", c.aggregate)
+		}
+
+		c.discoverRange, err = settings.GetString("discover_range", influxDB2DefaultDiscoverRange)
+		if err != nil {
+			return nil, err
+		} else if !influxDB2DurationRegexp.MatchString(c.discoverRange) {
+			return nil, fmt.Errorf("invalid %q discovery range", c.discoverRange)
+		}
+
+		c.timeout, err = settings.GetInt("timeout", defaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		c.allowInsecure, err = settings.GetBool("allow_insecure_tls", false)
+		if err != nil {
+			return nil, err
+		}
+
+		mapping, err = settings.GetMap("mapping", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if mapping != nil {
+			c.mapping.Source, err = mapping.GetStringSlice("source", nil)
+			if err != nil {
+				return nil, err
+			}
+
+			c.mapping.Metric, err = mapping.GetStringSlice("metric", nil)
+			if err != nil {
+				return nil, err
+			}
+
+			glue, err = mapping.GetString("glue", ".")
+			if err != nil {
+				return nil, err
+			} else if glue != "" {
+				c.mapping.Glue = glue
+			}
+		}
+
+		// Check remote instance URL
+		_, err = url.Parse(c.url)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse URL: %s", err)
+		}
+
+		c.client = httputil.NewClient(time.Duration(c.timeout)*time.Second, true, c.allowInsecure)
+
+		return c, nil
+	}
+
+	// Register type for catalog dump
+	gob.Register(map[string]string{})
+}
+
+type influxDB2Connector struct {
+	name          string
+	url           string
+	token         string
+	org           string
+	bucket        string
+	aggregate     string
+	discoverRange string
+	timeout       int
+	allowInsecure bool
+	mapping       *influxDBMapping
+	client        *http.Client
+	logger        *logger.Logger
+}
+
+func (c *influxDB2Connector) Name() string {
+	return c.name
+}
+
+func (c *influxDB2Connector) Points(ctx context.Context, q *series.Query) ([]series.Series, error) {
+	var queries []string
+
+	l := len(q.Metrics)
+	if l == 0 {
+		return nil, fmt.Errorf("requested metrics list is empty")
+	}
+
+	step := q.EndTime.Sub(q.StartTime) / time.Duration(q.Sample)
+	if step < time.Second {
+		step = time.Second
+	}
+
+	// Prepare query, each metric being yielded as a distinct result
+	for i, m := range q.Metrics {
+		var tags map[string]string
+
+		measurement, err := m.Attributes.GetString("measurement", "")
+		if err != nil || measurement == "" {
+			return nil, errors.Wrap(ErrInvalidAttribute, "measurement")
+		}
+
+		field, err := m.Attributes.GetString("field", "")
+		if err != nil || field == "" {
+			return nil, errors.Wrap(ErrInvalidAttribute, "field")
+		}
+
+		if v, err := m.Attributes.GetInterface("tags", nil); err != nil {
+			return nil, errors.Wrap(ErrInvalidAttribute, "tags")
+		} else if v, ok := v.(map[string]string); !ok {
+			return nil, errors.Wrap(ErrInvalidAttribute, "tags")
+		} else {
+			tags = v
+		}
+
+		filters := []string{
+			"r._measurement == " + influxDB2String(measurement),
+			"r._field == " + influxDB2String(field),
+		}
+
+		filters = append(filters, influxDB2TagFilters(tags)...)
+
+		queries = append(queries, fmt.Sprintf(
+			"from(bucket: %s)\n"+
+				"  |> range(start: %s, stop: %s)\n"+
+				"  |> filter(fn: (r) => %s)\n"+
+				"  |> group()\n"+
+				"  |> aggregateWindow(every: %ds, fn: %s, createEmpty: false)\n"+
+				"  |> keep(columns: [\"_time\", \"_value\"])\n"+
+				"  |> yield(name: \"series%d\")",
+			influxDB2String(c.bucket),
+			q.StartTime.UTC().Format(time.RFC3339),
+			q.EndTime.UTC().Format(time.RFC3339),
+			strings.Join(filters, " and "),
+			int64(step/time.Second),
+			c.aggregate,
+			i,
+		))
+	}
+
+	results := make([]series.Series, l)
+
+	// Execute query and parse results received from back-end
+	err := c.query(ctx, strings.Join(queries, "\n\n"), func(row map[string]string) error {
+		idx, err := strconv.Atoi(strings.TrimPrefix(row["result"], "series"))
+		if err != nil || idx < 0 || idx >= l {
+			return fmt.Errorf("unexpected %q result", row["result"])
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, row["_time"])
+		if err != nil {
+			return fmt.Errorf("failed to parse time: %s", row["_time"])
+		}
+
+		value := math.NaN()
+		if row["_value"] != "" {
+			value, err = strconv.ParseFloat(row["_value"], 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse value: %s", row["_value"])
+			}
+		}
+
+		results[idx].Points = append(results[idx].Points, series.Point{
+			Time:  t,
+			Value: series.Value(value),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch points: %s", err)
+	}
+
+	return results, nil
+}
+
+func (c *influxDB2Connector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
+	// Retrieve measurements list
+	measurements, err := c.schemaValues(ctx, fmt.Sprintf(
+		"schema.measurements(bucket: %s, start: %s)",
+		influxDB2String(c.bucket),
+		c.discoverRange,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to fetch measurements: %s", err)
+	}
+
+	// Retrieve series (i.e. fields and mapped tags values combinations) for each measurement
+	for _, measurement := range measurements {
+		if err = c.refreshMeasurement(ctx, measurement, output); err != nil {
+			return fmt.Errorf("failed to fetch %q measurement series: %s", measurement, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *influxDB2Connector) refreshMeasurement(ctx context.Context, measurement string,
+	output chan<- *catalog.Record) error {
+	fields, err := c.schemaValues(ctx, fmt.Sprintf(
+		"schema.measurementFieldKeys(bucket: %s, measurement: %s, start: %s)",
+		influxDB2String(c.bucket),
+		influxDB2String(measurement),
+		c.discoverRange,
+	))
+	if err != nil {
+		return err
+	}
+
+	tagKeys, err := c.schemaValues(ctx, fmt.Sprintf(
+		"schema.measurementTagKeys(bucket: %s, measurement: %s, start: %s)",
+		influxDB2String(c.bucket),
+		influxDB2String(measurement),
+		c.discoverRange,
+	))
+	if err != nil {
+		return err
+	}
+
+	existing := set.New()
+	for _, key := range tagKeys {
+		existing.Add(key)
+	}
+
+	// Only retrieve values of the tags used by mapping, as other tags are grouped together when fetching points
+	keys := []string{}
+	for _, item := range append(append([]string{}, c.mapping.Source...), c.mapping.Metric...) {
+		key := strings.TrimPrefix(item, "column:")
+		if key != item && !strings.HasPrefix(key, "_") && existing.Has(key) {
+			keys = append(keys, key)
+			existing.Remove(key)
+		}
+	}
+
+	// Walk through existing tags values combinations, restricting each tag values lookup to the previous ones
+	var walk func(tags map[string]string, keys []string) error
+
+	walk = func(tags map[string]string, keys []string) error {
+		if len(keys) == 0 {
+			for _, field := range fields {
+				c.emitRecord(measurement, field, tags, output)
+			}
+
+			return nil
+		}
+
+		filters := append([]string{"r._measurement == " + influxDB2String(measurement)}, influxDB2TagFilters(tags)...)
+
+		values, err := c.schemaValues(ctx, fmt.Sprintf(
+			"schema.tagValues(bucket: %s, tag: %s, predicate: (r) => %s, start: %s)",
+			influxDB2String(c.bucket),
+			influxDB2String(keys[0]),
+			strings.Join(filters, " and "),
+			c.discoverRange,
+		))
+		if err != nil {
+			return err
+		}
+
+		for _, value := range values {
+			next := make(map[string]string, len(tags)+1)
+			for k, v := range tags {
+				next[k] = v
+			}
+			next[keys[0]] = value
+
+			if err := walk(next, keys[1:]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return walk(map[string]string{}, keys)
+}
+
+func (c *influxDB2Connector) emitRecord(measurement, field string, tags map[string]string,
+	output chan<- *catalog.Record) {
+	var parts []string
+
+	seriesColumns := map[string]string{"name": measurement}
+	for key, value := range tags {
+		seriesColumns[key] = value
+	}
+
+	recordTags := make(map[string]string)
+
+	// Map source
+	for _, item := range c.mapping.Source {
+		term, part := mapKey(seriesColumns, item)
+		if part != "" {
+			if term != "" {
+				recordTags[term] = part
+			}
+			parts = append(parts, part)
+		}
+	}
+	sourceName := strings.Join(parts, c.mapping.Glue)
+
+	// Map metric
+	parts = []string{}
+	for _, item := range c.mapping.Metric {
+		term, part := mapKey(seriesColumns, item)
+		if part != "" {
+			if term != "" {
+				recordTags[term] = part
+			}
+			parts = append(parts, part)
+		}
+	}
+	parts = append(parts, field)
+	metricName := strings.Join(parts, c.mapping.Glue)
+
+	if sourceName == "" {
+		c.logger.Warning("unable to map source for %q measurement", measurement)
+		return
+	}
+
+	output <- &catalog.Record{
+		Origin: c.name,
+		Source: sourceName,
+		Metric: metricName,
+		Attributes: &maputil.Map{
+			"measurement": measurement,
+			"field":       field,
+			"tags":        recordTags,
+		},
+	}
+}
+
+// schemaValues executes a Flux schema function call, returning the resulting values.
+func (c *influxDB2Connector) schemaValues(ctx context.Context, call string) ([]string, error) {
+	values := []string{}
+
+	err := c.query(ctx, "import \"influxdata/influxdb/schema\"\n\n"+call, func(row map[string]string) error {
+		values = append(values, row["_value"])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (c *influxDB2Connector) query(ctx context.Context, query string, fn func(map[string]string) error) error {
+	body, err := json.Marshal(influxDB2Query{
+		Query: query,
+		Type:  "flux",
+		Dialect: influxDB2Dialect{
+			Header:         true,
+			Delimiter:      ",",
+			Annotations:    []string{},
+			DateTimeFormat: "RFC3339",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to marshal query: %s", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		c.url+influxDB2URLQuery+"?"+url.Values{"org": []string{c.org}}.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to set up HTTP request: %s", err)
+	}
+	req.Header.Add("User-Agent", "facette/"+version.Version)
+	req.Header.Set("Authorization", "Token "+c.token)
+	req.Header.Set("Accept", "application/csv")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to perform HTTP request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		er := influxDB2ErrorResponse{}
+		if err := httputil.BindJSON(resp, &er); err != nil {
+			return fmt.Errorf("unexpected HTTP status code %d", resp.StatusCode)
+		}
+
+		return fmt.Errorf("%s", er.Message)
+	}
+
+	return influxDB2ParseCSV(resp.Body, fn)
+}
+
+func influxDB2ParseCSV(r io.Reader, fn func(map[string]string) error) error {
+	var columns []string
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to parse CSV data: %s", err)
+		}
+
+		// Tables having different schemas are separated by a new header row
+		if len(record) > 2 && record[1] == "result" && record[2] == "table" {
+			columns = record
+			continue
+		} else if columns == nil {
+			return fmt.Errorf("unable to parse CSV data: missing header row")
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// influxDB2TagFilters returns Flux filter expressions matching tags values, ordered by tag key.
+func influxDB2TagFilters(tags map[string]string) []string {
+	keys := []string{}
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := []string{}
+	for _, key := range keys {
+		filters = append(filters, fmt.Sprintf("r[%s] == %s", influxDB2String(key), influxDB2String(tags[key])))
+	}
+
+	return filters
+}
+
+func influxDB2String(s string) string {
+	return `"` + influxDB2StringReplacer.Replace(s) + `"`
+}
+
+type influxDB2Query struct {
+	Query   string           `json:"query"`
+	Type    string           `json:"type"`
+	Dialect influxDB2Dialect `json:"dialect"`
+}
+
+type influxDB2Dialect struct {
+	Header         bool     `json:"header"`
+	Delimiter      string   `json:"delimiter"`
+	Annotations    []string `json:"annotations"`
+	DateTimeFormat string   `json:"dateTimeFormat"`
+}
+
+type influxDB2ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
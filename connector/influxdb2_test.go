@@ -0,0 +1,212 @@
+// +build !disable_connector_influxdb2
+
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/maputil"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInfluxDB2PointsQuery = `from(bucket: "telegraf")
+  |> range(start: 2017-07-14T02:40:00Z, stop: 2017-07-14T02:50:00Z)
+  |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage_idle" and r["cpu"] == "cpu0" and ` +
+	`r["host"] == "host1")
+  |> group()
+  |> aggregateWindow(every: 60s, fn: mean, createEmpty: false)
+  |> keep(columns: ["_time", "_value"])
+  |> yield(name: "series0")`
+
+func Test_InfluxDB2_Refresh(t *testing.T) {
+	responses := map[string]string{
+		`schema.measurements(bucket: "telegraf", start: -24h)`: "cpu",
+		`schema.measurementFieldKeys(bucket: "telegraf", measurement: "cpu", start: -24h)`: "usage_idle\nusage_user",
+		`schema.measurementTagKeys(bucket: "telegraf", measurement: "cpu", start: -24h)`: "_field\n_measurement\n" +
+			"_start\n_stop\ncpu\nhost\nregion",
+		`schema.tagValues(bucket: "telegraf", tag: "host", predicate: (r) => r._measurement == "cpu", ` +
+			`start: -24h)`: "host1\nhost2",
+		`schema.tagValues(bucket: "telegraf", tag: "cpu", predicate: (r) => r._measurement == "cpu" and ` +
+			`r["host"] == "host1", start: -24h)`: "cpu0\ncpu1",
+		`schema.tagValues(bucket: "telegraf", tag: "cpu", predicate: (r) => r._measurement == "cpu" and ` +
+			`r["host"] == "host2", start: -24h)`: "cpu0",
+	}
+
+	ts := testInfluxDB2Server(t, func(query string) string {
+		call := strings.TrimPrefix(query, "import \"influxdata/influxdb/schema\"\n\n")
+
+		values, ok := responses[call]
+		if !ok {
+			t.Errorf("unexpected query: %s", query)
+			return ""
+		}
+
+		data := ",result,table,_value\r\n"
+		for _, value := range strings.Split(values, "\n") {
+			data += ",_result,0," + value + "\r\n"
+		}
+
+		return data
+	})
+	defer ts.Close()
+
+	c := testConnector(t, "influxdb2", &maputil.Map{
+		"url":    ts.URL,
+		"token":  "secret",
+		"org":    "myorg",
+		"bucket": "telegraf",
+		"mapping": map[string]interface{}{
+			"source": []string{"column:host"},
+			"metric": []string{"name", "column:cpu"},
+		},
+	})
+
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	metrics := []string{}
+	for _, record := range records {
+		metrics = append(metrics, record.Source+"/"+record.Metric)
+	}
+
+	assert.Equal(t, []string{
+		"host1/cpu.cpu0.usage_idle",
+		"host1/cpu.cpu0.usage_user",
+		"host1/cpu.cpu1.usage_idle",
+		"host1/cpu.cpu1.usage_user",
+		"host2/cpu.cpu0.usage_idle",
+		"host2/cpu.cpu0.usage_user",
+	}, metrics)
+
+	assert.Equal(t, &maputil.Map{
+		"measurement": "cpu",
+		"field":       "usage_user",
+		"tags":        map[string]string{"host": "host2", "cpu": "cpu0"},
+	}, records[5].Attributes)
+}
+
+func Test_InfluxDB2_Points(t *testing.T) {
+	ts := testInfluxDB2Server(t, func(query string) string {
+		assert.Equal(t, testInfluxDB2PointsQuery+"\n\n"+strings.Replace(
+			strings.Replace(testInfluxDB2PointsQuery, "host1", "host2", 1), "series0", "series1", 1), query)
+
+		return ",result,table,_time,_value\r\n" +
+			",series0,0,2017-07-14T02:40:00Z,0.25\r\n" +
+			",series0,0,2017-07-14T02:41:00Z,\r\n" +
+			",series0,0,2017-07-14T02:42:00Z,0.5\r\n"
+	})
+	defer ts.Close()
+
+	c := testConnector(t, "influxdb2", &maputil.Map{
+		"url":    ts.URL,
+		"token":  "secret",
+		"org":    "myorg",
+		"bucket": "telegraf",
+		"mapping": map[string]interface{}{
+			"source": []string{"column:host"},
+			"metric": []string{"name", "column:cpu"},
+		},
+	})
+
+	cat := catalog.New("influxdb2", c)
+	for _, host := range []string{"host1", "host2"} {
+		assert.Nil(t, cat.Insert(&catalog.Record{
+			Origin: "influxdb2",
+			Source: host,
+			Metric: "cpu.cpu0.usage_idle",
+			Attributes: &maputil.Map{
+				"measurement": "cpu",
+				"field":       "usage_idle",
+				"tags":        map[string]string{"host": host, "cpu": "cpu0"},
+			},
+		}))
+	}
+
+	metrics := []*catalog.Metric{}
+	for _, host := range []string{"host1", "host2"} {
+		m, err := cat.Metric("influxdb2", host, "cpu.cpu0.usage_idle")
+		assert.Nil(t, err)
+
+		metrics = append(metrics, m)
+	}
+
+	startTime := time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC)
+
+	result, err := c.Points(context.Background(), &series.Query{
+		StartTime: startTime,
+		EndTime:   startTime.Add(10 * time.Minute),
+		Sample:    10,
+		Metrics:   metrics,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+
+	assert.Equal(t, 3, len(result[0].Points))
+	assert.Equal(t, series.Point{Time: startTime, Value: 0.25}, result[0].Points[0])
+	assert.True(t, result[0].Points[1].Value.IsNaN())
+	assert.Equal(t, series.Point{Time: startTime.Add(2 * time.Minute), Value: 0.5}, result[0].Points[2])
+	assert.Nil(t, result[1].Points)
+}
+
+func Test_InfluxDB2_ParseCSV(t *testing.T) {
+	data := ",result,table,_measurement,_field,host\r\n" +
+		",_result,0,cpu,usage_idle,host1\r\n" +
+		",_result,0,cpu,usage_user,host1\r\n" +
+		"\r\n" +
+		",result,table,_measurement,_field,host,device\r\n" +
+		",_result,1,disk,free,host2,sda1\r\n"
+
+	rows := []map[string]string{}
+	assert.Nil(t, influxDB2ParseCSV(strings.NewReader(data), func(row map[string]string) error {
+		rows = append(rows, row)
+		return nil
+	}))
+
+	assert.Equal(t, []map[string]string{
+		{"": "", "result": "_result", "table": "0", "_measurement": "cpu", "_field": "usage_idle", "host": "host1"},
+		{"": "", "result": "_result", "table": "0", "_measurement": "cpu", "_field": "usage_user", "host": "host1"},
+		{"": "", "result": "_result", "table": "1", "_measurement": "disk", "_field": "free", "host": "host2",
+			"device": "sda1"},
+	}, rows)
+}
+
+func Test_InfluxDB2_String(t *testing.T) {
+	assert.Equal(t, `"cpu"`, influxDB2String("cpu"))
+	assert.Equal(t, `"a \"quoted\" \\ value \${x}"`, influxDB2String(`a "quoted" \ value ${x}`))
+}
+
+func testInfluxDB2Server(t *testing.T, handler func(query string) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != influxDB2URLQuery {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.Equal(t, "myorg", r.URL.Query().Get("org"))
+		assert.Equal(t, "Token secret", r.Header.Get("Authorization"))
+
+		q := influxDB2Query{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&q))
+		assert.Equal(t, "flux", q.Type)
+
+		rw.Header().Set("Content-Type", "text/csv")
+		rw.Write([]byte(handler(q.Query)))
+	}))
+}
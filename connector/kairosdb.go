@@ -4,6 +4,7 @@ package connector
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -101,7 +102,7 @@ func (c *kairosDBConnector) Name() string {
 	return c.name
 }
 
-func (c *kairosDBConnector) Points(query *series.Query) ([]series.Series, error) {
+func (c *kairosDBConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
 	if len(query.Metrics) == 0 {
 		return nil, fmt.Errorf("requested metrics list is empty")
 	}
@@ -156,7 +157,7 @@ func (c *kairosDBConnector) Points(query *series.Query) ([]series.Series, error)
 		return nil, fmt.Errorf("unable to marshal tags request: %s", err)
 	}
 
-	req, err := http.NewRequest("POST", c.url+kairosDBURLDatapointsQuery, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url+kairosDBURLDatapointsQuery, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("unable to set up HTTP request: %s", err)
 	}
@@ -190,14 +191,14 @@ func (c *kairosDBConnector) Points(query *series.Query) ([]series.Series, error)
 	return result, nil
 }
 
-func (c *kairosDBConnector) Refresh(output chan<- *catalog.Record) error {
+func (c *kairosDBConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
 	// Prepare source tags set (used for tags filtering)
 	tags := set.New()
 	for _, t := range c.sourceTags {
 		tags.Add(t)
 	}
 
-	req, err := http.NewRequest("GET", c.url+kairosDBURLMetricNames, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url+kairosDBURLMetricNames, nil)
 	if err != nil {
 		return fmt.Errorf("unable to set up HTTP request: %s", err)
 	}
@@ -237,7 +238,7 @@ func (c *kairosDBConnector) Refresh(output chan<- *catalog.Record) error {
 			return fmt.Errorf("unable to marshal tags request: %s", err)
 		}
 
-		req, err = http.NewRequest("POST", c.url+kairosDBURLDatapointsQuery+"/tags", bytes.NewReader(body))
+		req, err = http.NewRequestWithContext(ctx, "POST", c.url+kairosDBURLDatapointsQuery+"/tags", bytes.NewReader(body))
 		if err != nil {
 			return fmt.Errorf("unable to set up HTTP request: %s", err)
 		}
@@ -0,0 +1,24 @@
+package connector
+
+import "strings"
+
+type influxDBMapping struct {
+	Source []string
+	Metric []string
+	Glue   string
+}
+
+func mapKey(seriesColumns map[string]string, item string) (string, string) {
+	if item == "name" {
+		return "", seriesColumns["name"]
+	} else if strings.HasPrefix(item, "column:") {
+		// Try to match row column
+		name := strings.TrimPrefix(item, "column:")
+		if value, ok := seriesColumns[name]; ok {
+			return name, value
+		}
+	}
+
+	// Nothing matched
+	return "", ""
+}
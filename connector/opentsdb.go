@@ -0,0 +1,351 @@
+// +build !disable_connector_opentsdb
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/facette/set"
+	"facette.io/facette/version"
+	"facette.io/httputil"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/pkg/errors"
+)
+
+const (
+	openTSDBDefaultLookupLimit  = 25000
+	openTSDBDefaultSuggestLimit = 10000
+	openTSDBURLQuery            = "/api/query"
+	openTSDBURLSearchLookup     = "/api/search/lookup"
+	openTSDBURLSuggest          = "/api/suggest"
+)
+
+var (
+	openTSDBDefaultSourceTags = []string{
+		"host",
+	}
+
+	openTSDBDefaultAggregators = []string{
+		"avg",
+		"max",
+		"min",
+	}
+)
+
+func init() {
+	connectors["opentsdb"] = func(name string, settings *maputil.Map, logger *logger.Logger) (Connector, error) {
+		var err error
+
+		c := &openTSDBConnector{
+			name:   name,
+			logger: logger,
+		}
+
+		// Get connector handler settings
+		c.url, err = settings.GetString("url", "")
+		if err != nil {
+			return nil, err
+		} else if c.url == "" {
+			return nil, ErrMissingConnectorSetting("url")
+		}
+		c.url = normalizeURL(c.url)
+
+		c.aggregators, err = settings.GetStringSlice("aggregators", openTSDBDefaultAggregators)
+		if err != nil {
+			return nil, err
+		}
+
+		c.sourceTags, err = settings.GetStringSlice("source_tags", openTSDBDefaultSourceTags)
+		if err != nil {
+			return nil, err
+		}
+
+		c.suggestLimit, err = settings.GetInt("suggest_limit", openTSDBDefaultSuggestLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		c.lookupLimit, err = settings.GetInt("lookup_limit", openTSDBDefaultLookupLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		c.timeout, err = settings.GetInt("timeout", defaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		c.allowInsecure, err = settings.GetBool("allow_insecure_tls", false)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check remote instance URL
+		_, err = url.Parse(c.url)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse URL: %s", err)
+		}
+
+		c.client = httputil.NewClient(time.Duration(c.timeout)*time.Second, true, c.allowInsecure)
+
+		return c, nil
+	}
+}
+
+type openTSDBConnector struct {
+	name          string
+	url           string
+	aggregators   []string
+	sourceTags    []string
+	suggestLimit  int
+	lookupLimit   int
+	timeout       int
+	allowInsecure bool
+	client        *http.Client
+	logger        *logger.Logger
+}
+
+func (c *openTSDBConnector) Name() string {
+	return c.name
+}
+
+func (c *openTSDBConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("requested metrics list is empty")
+	}
+
+	step := query.EndTime.Sub(query.StartTime) / time.Duration(query.Sample)
+	sampling := step.Nanoseconds() / 1000000
+	if sampling < 1000 {
+		sampling = 1000
+	}
+
+	q := openTSDBQuery{
+		Start:     query.StartTime.Unix() * 1000,
+		End:       query.EndTime.Unix() * 1000,
+		ShowQuery: true,
+		Queries:   []openTSDBSubQuery{},
+	}
+
+	for _, m := range query.Metrics {
+		var tag []string
+
+		metric, err := m.Attributes.GetString("metric", "")
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidAttribute, "metric")
+		}
+
+		aggregator, err := m.Attributes.GetString("aggregator", "")
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidAttribute, "aggregator")
+		}
+
+		if v, err := m.Attributes.GetInterface("tag", nil); err != nil {
+			return nil, errors.Wrap(ErrInvalidAttribute, "tag")
+		} else if v, ok := v.([]string); !ok || len(v) != 2 {
+			return nil, errors.Wrap(ErrInvalidAttribute, "tag")
+		} else {
+			tag = v
+		}
+
+		q.Queries = append(q.Queries, openTSDBSubQuery{
+			Aggregator: aggregator,
+			Metric:     metric,
+			Tags:       map[string]string{tag[0]: tag[1]},
+			Downsample: fmt.Sprintf("%dms-%s", sampling, aggregator),
+		})
+	}
+
+	body, err := json.Marshal(q)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal points request: %s", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url+openTSDBURLQuery, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("unable to set up HTTP request: %s", err)
+	}
+	req.Header.Add("User-Agent", "facette/"+version.Version)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to perform HTTP request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	// OpenTSDB replies with a 400 status code and an error object on unknown metrics or tags
+	if resp.StatusCode != http.StatusOK {
+		er := openTSDBErrorResponse{}
+		if err := httputil.BindJSON(resp, &er); err != nil {
+			return nil, fmt.Errorf("unexpected HTTP status code %d", resp.StatusCode)
+		}
+
+		return nil, fmt.Errorf("failed to fetch points: %s", er.Error.Message)
+	}
+
+	qr := []openTSDBQueryResult{}
+	if err := httputil.BindJSON(resp, &qr); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal JSON data: %s", err)
+	}
+
+	// Results having no data points are omitted from the response, thus rely on the query index to map them back
+	result := make([]series.Series, len(query.Metrics))
+	for _, r := range qr {
+		if r.Query.Index < 0 || r.Query.Index >= len(result) {
+			return nil, fmt.Errorf("unexpected query index %d in response", r.Query.Index)
+		}
+
+		s := series.Series{}
+		for key, value := range r.DataPoints {
+			ts, err := strconv.ParseInt(key, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse time: %s", key)
+			}
+
+			s.Points = append(s.Points, series.Point{
+				Time:  time.Unix(ts, 0),
+				Value: series.Value(value),
+			})
+		}
+
+		sort.Slice(s.Points, func(i, j int) bool {
+			return s.Points[i].Time.Before(s.Points[j].Time)
+		})
+
+		result[r.Query.Index] = s
+	}
+
+	return result, nil
+}
+
+func (c *openTSDBConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
+	// Prepare source tags set (used for tags filtering)
+	tags := set.New()
+	for _, t := range c.sourceTags {
+		tags.Add(t)
+	}
+
+	// Retrieve metrics list
+	metrics := []string{}
+	err := c.get(ctx, openTSDBURLSuggest, url.Values{
+		"type": []string{"metrics"},
+		"max":  []string{strconv.Itoa(c.suggestLimit)},
+	}, &metrics)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve metrics associated tags
+	for _, metric := range metrics {
+		lr := openTSDBLookupResponse{}
+		err := c.get(ctx, openTSDBURLSearchLookup, url.Values{
+			"m":     []string{metric},
+			"limit": []string{strconv.Itoa(c.lookupLimit)},
+		}, &lr)
+		if err != nil {
+			c.logger.Warning("unable to lookup %q metric: %s", metric, err)
+			continue
+		}
+
+		// Skip already emitted tag pairs, as series usually differ by tags not used for sources mapping
+		seen := set.New()
+
+		for _, r := range lr.Results {
+			for key, value := range r.Tags {
+				if !tags.Has(key) || seen.Has(key+"="+value) {
+					continue
+				}
+				seen.Add(key + "=" + value)
+
+				for _, aggr := range c.aggregators {
+					output <- &catalog.Record{
+						Origin: c.name,
+						Source: value,
+						Metric: metric + "/" + aggr,
+						Attributes: &maputil.Map{
+							"metric":     metric,
+							"aggregator": aggr,
+							"tag":        []string{key, value},
+						},
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *openTSDBConnector) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("unable to set up HTTP request: %s", err)
+	}
+	req.Header.Add("User-Agent", "facette/"+version.Version)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to perform HTTP request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if err := httputil.BindJSON(resp, out); err != nil {
+		return fmt.Errorf("unable to unmarshal JSON data: %s", err)
+	}
+
+	return nil
+}
+
+type openTSDBQuery struct {
+	Start     int64              `json:"start"`
+	End       int64              `json:"end,omitempty"`
+	ShowQuery bool               `json:"showQuery"`
+	Queries   []openTSDBSubQuery `json:"queries"`
+}
+
+type openTSDBSubQuery struct {
+	Aggregator string            `json:"aggregator"`
+	Metric     string            `json:"metric"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Downsample string            `json:"downsample,omitempty"`
+}
+
+type openTSDBQueryResult struct {
+	Metric     string             `json:"metric"`
+	Tags       map[string]string  `json:"tags"`
+	Query      openTSDBQueryIndex `json:"query"`
+	DataPoints map[string]float64 `json:"dps"`
+}
+
+type openTSDBQueryIndex struct {
+	Index int `json:"index"`
+}
+
+type openTSDBLookupResponse struct {
+	Metric  string                 `json:"metric"`
+	Results []openTSDBLookupResult `json:"results"`
+}
+
+type openTSDBLookupResult struct {
+	Metric string            `json:"metric"`
+	Tags   map[string]string `json:"tags"`
+}
+
+type openTSDBErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
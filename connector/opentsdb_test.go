@@ -0,0 +1,174 @@
+// +build !disable_connector_opentsdb
+
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/maputil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testOpenTSDBSuggest = `["net.if.octets","sys.load"]`
+
+	testOpenTSDBLookupOctets = `{
+  "type": "LOOKUP",
+  "metric": "net.if.octets",
+  "results": [
+    {"tsuid": "000001000001000001", "metric": "net.if.octets", "tags": {"host": "sw1", "iface": "ge-0/0/1"}},
+    {"tsuid": "000001000001000002", "metric": "net.if.octets", "tags": {"host": "sw1", "iface": "ge-0/0/2"}},
+    {"tsuid": "000001000002000001", "metric": "net.if.octets", "tags": {"host": "sw2", "iface": "ge-0/0/1"}}
+  ],
+  "totalResults": 3
+}`
+
+	testOpenTSDBLookupLoad = `{
+  "type": "LOOKUP",
+  "metric": "sys.load",
+  "results": [
+    {"tsuid": "000002000003", "metric": "sys.load", "tags": {"device": "rtr1"}}
+  ],
+  "totalResults": 1
+}`
+
+	testOpenTSDBQuery = `[
+  {
+    "metric": "sys.load",
+    "tags": {"host": "sw2"},
+    "aggregateTags": [],
+    "query": {"index": 1, "metric": "sys.load", "aggregator": "max"},
+    "dps": {"1500000060": 0.5, "1500000000": 0.25}
+  },
+  {
+    "metric": "net.if.octets",
+    "tags": {"host": "sw1"},
+    "aggregateTags": ["iface"],
+    "query": {"index": 0, "metric": "net.if.octets", "aggregator": "avg"},
+    "dps": {"1500000000": 1024, "1500000060": 2048}
+  }
+]`
+)
+
+func Test_OpenTSDB_Refresh(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case openTSDBURLSuggest:
+			assert.Equal(t, "metrics", r.URL.Query().Get("type"))
+			rw.Write([]byte(testOpenTSDBSuggest))
+
+		case openTSDBURLSearchLookup:
+			switch r.URL.Query().Get("m") {
+			case "net.if.octets":
+				rw.Write([]byte(testOpenTSDBLookupOctets))
+			case "sys.load":
+				rw.Write([]byte(testOpenTSDBLookupLoad))
+			}
+
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := testConnector(t, "opentsdb", &maputil.Map{
+		"url":         ts.URL,
+		"aggregators": []string{"avg"},
+	})
+
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, "sw1", records[0].Source)
+	assert.Equal(t, "net.if.octets/avg", records[0].Metric)
+	assert.Equal(t, []string{"host", "sw1"}, (*records[0].Attributes)["tag"])
+	assert.Equal(t, "sw2", records[1].Source)
+	assert.Equal(t, "net.if.octets/avg", records[1].Metric)
+}
+
+func Test_OpenTSDB_Points(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openTSDBURLQuery {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		q := openTSDBQuery{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&q))
+		assert.Len(t, q.Queries, 3)
+		assert.Equal(t, "60000ms-avg", q.Queries[0].Downsample)
+		assert.Equal(t, map[string]string{"host": "sw1"}, q.Queries[0].Tags)
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(testOpenTSDBQuery))
+	}))
+	defer ts.Close()
+
+	c := testConnector(t, "opentsdb", &maputil.Map{
+		"url":         ts.URL,
+		"aggregators": []string{"avg"},
+	})
+
+	cat := catalog.New("opentsdb", c)
+	for _, r := range []*catalog.Record{
+		{Origin: "opentsdb", Source: "sw1", Metric: "net.if.octets/avg", Attributes: &maputil.Map{
+			"metric": "net.if.octets", "aggregator": "avg", "tag": []string{"host", "sw1"},
+		}},
+		{Origin: "opentsdb", Source: "sw2", Metric: "sys.load/max", Attributes: &maputil.Map{
+			"metric": "sys.load", "aggregator": "max", "tag": []string{"host", "sw2"},
+		}},
+		{Origin: "opentsdb", Source: "sw3", Metric: "sys.load/max", Attributes: &maputil.Map{
+			"metric": "sys.load", "aggregator": "max", "tag": []string{"host", "sw3"},
+		}},
+	} {
+		assert.Nil(t, cat.Insert(r))
+	}
+
+	metrics := []*catalog.Metric{}
+	for _, pair := range [][2]string{{"sw1", "net.if.octets/avg"}, {"sw2", "sys.load/max"}, {"sw3", "sys.load/max"}} {
+		m, err := cat.Metric("opentsdb", pair[0], pair[1])
+		assert.Nil(t, err)
+
+		metrics = append(metrics, m)
+	}
+
+	startTime := time.Unix(1500000000, 0)
+
+	result, err := c.Points(context.Background(), &series.Query{
+		StartTime: startTime,
+		EndTime:   startTime.Add(10 * time.Minute),
+		Sample:    10,
+		Metrics:   metrics,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result, 3)
+
+	assert.Equal(t, []series.Point{
+		{Time: startTime, Value: 1024},
+		{Time: startTime.Add(time.Minute), Value: 2048},
+	}, result[0].Points)
+	assert.Equal(t, []series.Point{
+		{Time: startTime, Value: 0.25},
+		{Time: startTime.Add(time.Minute), Value: 0.5},
+	}, result[1].Points)
+	assert.Nil(t, result[2].Points)
+}
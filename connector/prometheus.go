@@ -0,0 +1,333 @@
+// +build !disable_connector_prometheus
+
+package connector
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/facette/version"
+	"facette.io/httputil"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/pkg/errors"
+)
+
+const (
+	prometheusURLQueryRange = "/api/v1/query_range"
+	prometheusURLSeries     = "/api/v1/series"
+)
+
+var (
+	prometheusDefaultMatch = []string{
+		`{__name__=~".+"}`,
+	}
+
+	prometheusDefaultSourceLabels = []string{
+		"instance",
+	}
+
+	prometheusDefaultMetricLabels = []string{
+		"__name__",
+	}
+)
+
+func init() {
+	connectors["prometheus"] = func(name string, settings *maputil.Map, logger *logger.Logger) (Connector, error) {
+		var (
+			mapping maputil.Map
+			glue    string
+			err     error
+		)
+
+		c := &prometheusConnector{
+			name: name,
+			mapping: &prometheusMapping{
+				Source: prometheusDefaultSourceLabels,
+				Metric: prometheusDefaultMetricLabels,
+				Glue:   ".",
+			},
+			logger: logger,
+		}
+
+		// Load provider configuration
+		c.url, err = settings.GetString("url", "")
+		if err != nil {
+			return nil, err
+		} else if c.url == "" {
+			return nil, ErrMissingConnectorSetting("url")
+		}
+		c.url = normalizeURL(c.url)
+
+		c.timeout, err = settings.GetInt("timeout", defaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		c.allowInsecure, err = settings.GetBool("allow_insecure_tls", false)
+		if err != nil {
+			return nil, err
+		}
+
+		c.match, err = settings.GetStringSlice("match", prometheusDefaultMatch)
+		if err != nil {
+			return nil, err
+		}
+
+		mapping, err = settings.GetMap("mapping", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if mapping != nil {
+			c.mapping.Origin, err = mapping.GetStringSlice("origin", nil)
+			if err != nil {
+				return nil, err
+			}
+
+			c.mapping.Source, err = mapping.GetStringSlice("source", prometheusDefaultSourceLabels)
+			if err != nil {
+				return nil, err
+			}
+
+			c.mapping.Metric, err = mapping.GetStringSlice("metric", prometheusDefaultMetricLabels)
+			if err != nil {
+				return nil, err
+			}
+
+			glue, err = mapping.GetString("glue", ".")
+			if err != nil {
+				return nil, err
+			} else if glue != "" {
+				c.mapping.Glue = glue
+			}
+		}
+
+		// Check remote instance URL
+		_, err = url.Parse(c.url)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse URL: %s", err)
+		}
+
+		c.client = httputil.NewClient(time.Duration(c.timeout)*time.Second, true, c.allowInsecure)
+
+		return c, nil
+	}
+
+	// Register type for catalog dump
+	gob.Register(map[string]string{})
+}
+
+type prometheusConnector struct {
+	name          string
+	url           string
+	timeout       int
+	allowInsecure bool
+	match         []string
+	mapping       *prometheusMapping
+	client        *http.Client
+	logger        *logger.Logger
+}
+
+func (c *prometheusConnector) Name() string {
+	return c.name
+}
+
+func (c *prometheusConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("requested metrics list is empty")
+	}
+
+	step := query.EndTime.Sub(query.StartTime) / time.Duration(query.Sample)
+	if step < time.Second {
+		step = time.Second
+	}
+
+	result := make([]series.Series, len(query.Metrics))
+
+	for i, m := range query.Metrics {
+		var labels map[string]string
+
+		if v, err := m.Attributes.GetInterface("labels", nil); err != nil {
+			return nil, errors.Wrap(ErrInvalidAttribute, "labels")
+		} else if v, ok := v.(map[string]string); !ok {
+			return nil, errors.Wrap(ErrInvalidAttribute, "labels")
+		} else {
+			labels = v
+		}
+
+		params := url.Values{}
+		params.Set("query", prometheusBuildSelector(labels))
+		params.Set("start", strconv.FormatInt(query.StartTime.Unix(), 10))
+		params.Set("end", strconv.FormatInt(query.EndTime.Unix(), 10))
+		params.Set("step", strconv.FormatInt(int64(step/time.Second), 10))
+
+		pr := prometheusMatrixResponse{}
+		if err := c.get(ctx, prometheusURLQueryRange, params, &pr); err != nil {
+			return nil, err
+		} else if pr.Status != "success" {
+			return nil, fmt.Errorf("failed to fetch points: %s", pr.Error)
+		}
+
+		result[i] = series.Series{}
+
+		// Only the first matching series is kept, as the labels set is expected to be unique
+		if len(pr.Data.Result) == 0 {
+			continue
+		}
+
+		for _, v := range pr.Data.Result[0].Values {
+			point, err := prometheusParseValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse point: %s", err)
+			}
+
+			result[i].Points = append(result[i].Points, point)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *prometheusConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
+	params := url.Values{}
+	for _, match := range c.match {
+		params.Add("match[]", match)
+	}
+
+	sr := prometheusSeriesResponse{}
+	if err := c.get(ctx, prometheusURLSeries, params, &sr); err != nil {
+		return err
+	} else if sr.Status != "success" {
+		return fmt.Errorf("failed to fetch series: %s", sr.Error)
+	}
+
+	for _, labels := range sr.Data {
+		// Fall back to connector name if no origin labels are mapped or found
+		originName := prometheusMapLabels(labels, c.mapping.Origin, c.mapping.Glue)
+		if originName == "" {
+			originName = c.name
+		}
+
+		sourceName := prometheusMapLabels(labels, c.mapping.Source, c.mapping.Glue)
+		metricName := prometheusMapLabels(labels, c.mapping.Metric, c.mapping.Glue)
+
+		if sourceName == "" || metricName == "" {
+			c.logger.Warning("unable to map series %s", prometheusBuildSelector(labels))
+			continue
+		}
+
+		output <- &catalog.Record{
+			Origin: originName,
+			Source: sourceName,
+			Metric: metricName,
+			Attributes: &maputil.Map{
+				"labels": labels,
+			},
+		}
+	}
+
+	return nil
+}
+
+func (c *prometheusConnector) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("unable to set up HTTP request: %s", err)
+	}
+	req.Header.Add("User-Agent", "facette/"+version.Version)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to perform HTTP request: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if err := httputil.BindJSON(resp, out); err != nil {
+		return fmt.Errorf("unable to unmarshal JSON data: %s", err)
+	}
+
+	return nil
+}
+
+func prometheusBuildSelector(labels map[string]string) string {
+	keys := []string{}
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, strconv.Quote(labels[key])))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func prometheusMapLabels(labels map[string]string, keys []string, glue string) string {
+	parts := []string{}
+	for _, key := range keys {
+		if value, ok := labels[key]; ok && value != "" {
+			parts = append(parts, value)
+		}
+	}
+
+	return strings.Join(parts, glue)
+}
+
+func prometheusParseValue(v [2]interface{}) (series.Point, error) {
+	ts, ok := v[0].(float64)
+	if !ok {
+		return series.Point{}, fmt.Errorf("invalid timestamp %v", v[0])
+	}
+
+	s, ok := v[1].(string)
+	if !ok {
+		return series.Point{}, fmt.Errorf("invalid value %v", v[1])
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return series.Point{}, err
+	}
+
+	return series.Point{
+		Time:  time.Unix(int64(ts), 0),
+		Value: series.Value(value),
+	}, nil
+}
+
+type prometheusMapping struct {
+	Origin []string
+	Source []string
+	Metric []string
+	Glue   string
+}
+
+type prometheusSeriesResponse struct {
+	Status string              `json:"status"`
+	Error  string              `json:"error"`
+	Data   []map[string]string `json:"data"`
+}
+
+type prometheusMatrixResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]interface{}  `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
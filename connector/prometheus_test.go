@@ -0,0 +1,162 @@
+// +build !disable_connector_prometheus
+
+package connector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/maputil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPrometheusSeries = `{
+  "status": "success",
+  "data": [
+    {"__name__": "node_load1", "instance": "host1:9100", "job": "node"},
+    {"__name__": "node_load1", "instance": "host2:9100"},
+    {"__name__": "up", "job": "node"}
+  ]
+}`
+
+	testPrometheusQueryRange = `{
+  "status": "success",
+  "data": {
+    "resultType": "matrix",
+    "result": [
+      {
+        "metric": {"__name__": "node_load1", "instance": "host1:9100", "job": "node"},
+        "values": [[1500000000, "0.25"], [1500000060, "0.5"]]
+      }
+    ]
+  }
+}`
+
+	testPrometheusQueryRangeEmpty = `{"status": "success", "data": {"resultType": "matrix", "result": []}}`
+)
+
+func Test_Prometheus_Refresh(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prometheusURLSeries {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.Equal(t, []string{`{job="node"}`}, r.URL.Query()["match[]"])
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(testPrometheusSeries))
+	}))
+	defer ts.Close()
+
+	c := testConnector(t, "prometheus", &maputil.Map{
+		"url":   ts.URL,
+		"match": []string{`{job="node"}`},
+		"mapping": map[string]interface{}{
+			"origin": []string{"job"},
+			"source": []string{"instance"},
+			"metric": []string{"__name__"},
+		},
+	})
+
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, "node", records[0].Origin)
+	assert.Equal(t, "host1:9100", records[0].Source)
+	assert.Equal(t, "node_load1", records[0].Metric)
+	assert.Equal(t, map[string]string{
+		"__name__": "node_load1",
+		"instance": "host1:9100",
+		"job":      "node",
+	}, (*records[0].Attributes)["labels"])
+	assert.Equal(t, "prometheus", records[1].Origin)
+	assert.Equal(t, "host2:9100", records[1].Source)
+}
+
+func Test_Prometheus_Points(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prometheusURLQueryRange {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		q := r.URL.Query()
+		assert.Equal(t, "1500000000", q.Get("start"))
+		assert.Equal(t, "1500000600", q.Get("end"))
+		assert.Equal(t, "60", q.Get("step"))
+
+		rw.Header().Set("Content-Type", "application/json")
+
+		switch q.Get("query") {
+		case `{__name__="node_load1",instance="host1:9100",job="node"}`:
+			rw.Write([]byte(testPrometheusQueryRange))
+		default:
+			rw.Write([]byte(testPrometheusQueryRangeEmpty))
+		}
+	}))
+	defer ts.Close()
+
+	c := testConnector(t, "prometheus", &maputil.Map{
+		"url":   ts.URL,
+		"match": []string{`{job="node"}`},
+		"mapping": map[string]interface{}{
+			"origin": []string{"job"},
+			"source": []string{"instance"},
+			"metric": []string{"__name__"},
+		},
+	})
+
+	cat := catalog.New("prometheus", c)
+	for _, r := range []*catalog.Record{
+		{Origin: "node", Source: "host1:9100", Metric: "node_load1", Attributes: &maputil.Map{
+			"labels": map[string]string{"__name__": "node_load1", "instance": "host1:9100", "job": "node"},
+		}},
+		{Origin: "node", Source: "host3:9100", Metric: "node_load1", Attributes: &maputil.Map{
+			"labels": map[string]string{"__name__": "node_load1", "instance": "host3:9100", "job": "node"},
+		}},
+	} {
+		assert.Nil(t, cat.Insert(r))
+	}
+
+	metrics := []*catalog.Metric{}
+	for _, source := range []string{"host1:9100", "host3:9100"} {
+		m, err := cat.Metric("node", source, "node_load1")
+		assert.Nil(t, err)
+
+		metrics = append(metrics, m)
+	}
+
+	startTime := time.Unix(1500000000, 0)
+
+	result, err := c.Points(context.Background(), &series.Query{
+		StartTime: startTime,
+		EndTime:   startTime.Add(10 * time.Minute),
+		Sample:    10,
+		Metrics:   metrics,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+
+	assert.Equal(t, []series.Point{
+		{Time: startTime, Value: 0.25},
+		{Time: startTime.Add(time.Minute), Value: 0.5},
+	}, result[0].Points)
+	assert.Nil(t, result[1].Points)
+}
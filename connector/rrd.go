@@ -3,10 +3,10 @@
 package connector
 
 import (
+	"context"
 	"encoding/gob"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -77,7 +77,7 @@ func (c *rrdConnector) Name() string {
 	return c.name
 }
 
-func (c *rrdConnector) Points(q *series.Query) ([]series.Series, error) {
+func (c *rrdConnector) Points(ctx context.Context, q *series.Query) ([]series.Series, error) {
 	var stepMax time.Duration
 
 	if len(q.Metrics) == 0 {
@@ -135,10 +135,39 @@ func (c *rrdConnector) Points(q *series.Query) ([]series.Series, error) {
 		stepMax = q.EndTime.Sub(q.StartTime) / time.Duration(series.DefaultSample)
 	}
 
-	// Retrieve data points
-	data, err := xport.Xport(q.StartTime, q.EndTime, stepMax)
-	if err != nil {
-		return nil, err
+	// Retrieve data points, returning early if the context is done before the export completes (the underlying
+	// library call can't be interrupted)
+	type xportResult struct {
+		data rrd.XportResult
+		err  error
+	}
+
+	ch := make(chan xportResult, 1)
+
+	go func() {
+		data, err := xport.Xport(q.StartTime, q.EndTime, stepMax)
+		ch <- xportResult{data, err}
+	}()
+
+	var data rrd.XportResult
+
+	select {
+	case <-ctx.Done():
+		// Release export data once available
+		go func() {
+			if r := <-ch; r.err == nil {
+				r.data.FreeValues()
+			}
+		}()
+
+		return nil, ctx.Err()
+
+	case r := <-ch:
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		data = r.data
 	}
 
 	result := []series.Series{}
@@ -161,7 +190,7 @@ func (c *rrdConnector) Points(q *series.Query) ([]series.Series, error) {
 	return result, nil
 }
 
-func (c *rrdConnector) Refresh(output chan<- *catalog.Record) error {
+func (c *rrdConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
 	// Search for files and parse their path for source/metric pairs
 	walkFunc := func(path string, fi os.FileInfo, err error) error {
 		if err != nil {
@@ -226,40 +255,5 @@ func (c *rrdConnector) Refresh(output chan<- *catalog.Record) error {
 		return nil
 	}
 
-	return c.walk(c.path, "", walkFunc)
-}
-
-func (c *rrdConnector) walk(root, originalRoot string, walkFunc filepath.WalkFunc) error {
-	if _, err := os.Stat(root); err != nil {
-		c.logger.Error("%s", err)
-		return nil
-	}
-
-	// Walk root directory
-	return filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
-		var realPath string
-
-		if err != nil {
-			c.logger.Error("%s", err)
-			return nil
-		}
-
-		mode := fi.Mode() & os.ModeType
-		if mode == os.ModeSymlink {
-			// Follow symbolic link if evaluation succeeds
-			realPath, err = filepath.EvalSymlinks(path)
-			if err != nil {
-				c.logger.Error("%s", err)
-				return nil
-			}
-
-			return c.walk(realPath, path, walkFunc)
-		}
-
-		if originalRoot != "" {
-			path = originalRoot + strings.TrimPrefix(path, root)
-		}
-
-		return walkFunc(path, fi, err)
-	})
+	return walkDir(ctx, c.path, "", walkFunc, c.logger)
 }
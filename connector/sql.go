@@ -0,0 +1,333 @@
+// +build !disable_connector_sql
+
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/pkg/errors"
+)
+
+const (
+	sqlBindQuestion = iota
+	sqlBindDollar
+)
+
+var (
+	sqlDrivers = make(map[string]sqlDriver)
+
+	sqlParamRegexp = regexp.MustCompile(`(^|[^:]):(start|end|origin|source|metric)\b`)
+	sqlQuoteRegexp = regexp.MustCompile(`'(?:[^']|'')*'|"(?:[^"]|"")*"|` + "`[^`]*`" + `|--[^\n]*|(?s:/\*.*?\*/)`)
+)
+
+type sqlDriver struct {
+	name string
+	bind int
+}
+
+func init() {
+	connectors["sql"] = func(name string, settings *maputil.Map, logger *logger.Logger) (Connector, error) {
+		var (
+			driver string
+			dsn    string
+			err    error
+		)
+
+		c := &sqlConnector{
+			name:   name,
+			logger: logger,
+		}
+
+		// Get connector handler settings
+		driver, err = settings.GetString("driver", "")
+		if err != nil {
+			return nil, err
+		} else if driver == "" {
+			return nil, ErrMissingConnectorSetting("driver")
+		}
+
+		d, ok := sqlDrivers[driver]
+		if !ok {
+			return nil, fmt.Errorf("unsupported %q database driver", driver)
+		}
+
+		dsn, err = settings.GetString("dsn", "")
+		if err != nil {
+			return nil, err
+		} else if dsn == "" {
+			return nil, ErrMissingConnectorSetting("dsn")
+		}
+
+		c.catalogQuery, err = settings.GetString("catalog_query", "")
+		if err != nil {
+			return nil, err
+		} else if c.catalogQuery == "" {
+			return nil, ErrMissingConnectorSetting("catalog_query")
+		}
+
+		pointsQuery, err := settings.GetString("points_query", "")
+		if err != nil {
+			return nil, err
+		} else if pointsQuery == "" {
+			return nil, ErrMissingConnectorSetting("points_query")
+		}
+
+		c.epochTime, err = settings.GetBool("epoch_time", false)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rewrite points query named parameters into driver-specific placeholders
+		c.pointsQuery, c.pointsParams = sqlBindParams(pointsQuery, d.bind)
+
+		c.db, err = sql.Open(d.name, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open database: %s", err)
+		}
+
+		return c, nil
+	}
+}
+
+type sqlConnector struct {
+	name         string
+	catalogQuery string
+	pointsQuery  string
+	pointsParams []string
+	epochTime    bool
+	db           *sql.DB
+	logger       *logger.Logger
+}
+
+func (c *sqlConnector) Name() string {
+	return c.name
+}
+
+func (c *sqlConnector) Points(ctx context.Context, query *series.Query) ([]series.Series, error) {
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("requested metrics list is empty")
+	}
+
+	result := make([]series.Series, len(query.Metrics))
+
+	for i, m := range query.Metrics {
+		values := map[string]interface{}{}
+
+		for _, key := range []string{"origin", "source", "metric"} {
+			v, err := m.Attributes.GetString(key, "")
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidAttribute, key)
+			}
+			values[key] = v
+		}
+
+		if c.epochTime {
+			values["start"] = query.StartTime.Unix()
+			values["end"] = query.EndTime.Unix()
+		} else {
+			values["start"] = query.StartTime.UTC()
+			values["end"] = query.EndTime.UTC()
+		}
+
+		args := make([]interface{}, len(c.pointsParams))
+		for j, param := range c.pointsParams {
+			args[j] = values[param]
+		}
+
+		rows, err := c.db.QueryContext(ctx, c.pointsQuery, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch points: %s", err)
+		}
+
+		result[i] = series.Series{}
+
+		for rows.Next() {
+			var t, v interface{}
+
+			if err := rows.Scan(&t, &v); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan row: %s", err)
+			}
+
+			point, err := sqlParsePoint(t, v)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to parse point: %s", err)
+			}
+
+			result[i].Points = append(result[i].Points, point)
+		}
+
+		err = rows.Err()
+		rows.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch points: %s", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *sqlConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
+	rows, err := c.db.QueryContext(ctx, c.catalogQuery)
+	if err != nil {
+		return fmt.Errorf("failed to fetch catalog: %s", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to fetch catalog: %s", err)
+	}
+
+	// Map result columns on catalog records fields, origin being optional
+	indexes := map[string]int{}
+	for i, column := range columns {
+		indexes[strings.ToLower(column)] = i
+	}
+
+	for _, key := range []string{"source", "metric"} {
+		if _, ok := indexes[key]; !ok {
+			return fmt.Errorf("missing %q column in catalog query result", key)
+		}
+	}
+
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan row: %s", err)
+		}
+
+		origin := c.name
+		if idx, ok := indexes["origin"]; ok && values[idx].String != "" {
+			origin = values[idx].String
+		}
+
+		source, metric := values[indexes["source"]].String, values[indexes["metric"]].String
+		if source == "" || metric == "" {
+			c.logger.Warning("skipping row with empty source or metric")
+			continue
+		}
+
+		output <- &catalog.Record{
+			Origin: origin,
+			Source: source,
+			Metric: metric,
+			Attributes: &maputil.Map{
+				"origin": origin,
+				"source": source,
+				"metric": metric,
+			},
+		}
+	}
+
+	return rows.Err()
+}
+
+// Close closes the underlying database connections pool.
+func (c *sqlConnector) Close() error {
+	return c.db.Close()
+}
+
+func sqlBindParams(query string, bind int) (string, []string) {
+	var (
+		params = []string{}
+		result strings.Builder
+		last   int
+	)
+
+	bindFunc := func(match string) string {
+		m := sqlParamRegexp.FindStringSubmatch(match)
+		params = append(params, m[2])
+
+		if bind == sqlBindDollar {
+			return m[1] + "$" + strconv.Itoa(len(params))
+		}
+
+		return m[1] + "?"
+	}
+
+	// Only substitute parameters outside of quoted strings, quoted identifiers and comments
+	for _, loc := range sqlQuoteRegexp.FindAllStringIndex(query, -1) {
+		result.WriteString(sqlParamRegexp.ReplaceAllStringFunc(query[last:loc[0]], bindFunc))
+		result.WriteString(query[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	result.WriteString(sqlParamRegexp.ReplaceAllStringFunc(query[last:], bindFunc))
+
+	return result.String(), params
+}
+
+func sqlParsePoint(t, v interface{}) (series.Point, error) {
+	var (
+		point series.Point
+		err   error
+	)
+
+	switch t := t.(type) {
+	case time.Time:
+		point.Time = t
+
+	case int64:
+		point.Time = time.Unix(t, 0)
+
+	case float64:
+		point.Time = time.Unix(int64(t), 0)
+
+	case []byte, string:
+		s := fmt.Sprintf("%s", t)
+
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			point.Time = time.Unix(n, 0)
+		} else if point.Time, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			if point.Time, err = time.Parse("2006-01-02 15:04:05", s); err != nil {
+				return point, fmt.Errorf("invalid time %q", s)
+			}
+		}
+
+	default:
+		return point, fmt.Errorf("unsupported time type %T", t)
+	}
+
+	switch v := v.(type) {
+	case nil:
+		point.Value = series.Value(math.NaN())
+
+	case float64:
+		point.Value = series.Value(v)
+
+	case int64:
+		point.Value = series.Value(v)
+
+	case []byte, string:
+		var f float64
+
+		f, err = strconv.ParseFloat(fmt.Sprintf("%s", v), 64)
+		if err != nil {
+			return point, fmt.Errorf("invalid value %q", v)
+		}
+		point.Value = series.Value(f)
+
+	default:
+		return point, fmt.Errorf("unsupported value type %T", v)
+	}
+
+	return point, nil
+}
@@ -0,0 +1,9 @@
+// +build !disable_connector_sql,!disable_driver_mysql
+
+package connector
+
+import _ "github.com/go-sql-driver/mysql"
+
+func init() {
+	sqlDrivers["mysql"] = sqlDriver{name: "mysql", bind: sqlBindQuestion}
+}
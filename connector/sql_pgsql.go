@@ -0,0 +1,9 @@
+// +build !disable_connector_sql,!disable_driver_pgsql
+
+package connector
+
+import _ "github.com/lib/pq"
+
+func init() {
+	sqlDrivers["pgsql"] = sqlDriver{name: "postgres", bind: sqlBindDollar}
+}
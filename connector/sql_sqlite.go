@@ -0,0 +1,9 @@
+// +build !disable_connector_sql,!disable_driver_sqlite
+
+package connector
+
+import _ "github.com/mattn/go-sqlite3"
+
+func init() {
+	sqlDrivers["sqlite"] = sqlDriver{name: "sqlite3", bind: sqlBindQuestion}
+}
@@ -0,0 +1,143 @@
+// +build !disable_connector_sql,!disable_driver_sqlite
+
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SQL_BindParams(t *testing.T) {
+	query, params := sqlBindParams(
+		"select ts, value from points where name = :metric and ts::int between :start and :end",
+		sqlBindDollar,
+	)
+	assert.Equal(t, "select ts, value from points where name = $1 and ts::int between $2 and $3", query)
+	assert.Equal(t, []string{"metric", "start", "end"}, params)
+
+	query, params = sqlBindParams("select * from points where host = :source and name = :metric", sqlBindQuestion)
+	assert.Equal(t, "select * from points where host = ? and name = ?", query)
+	assert.Equal(t, []string{"source", "metric"}, params)
+
+	query, params = sqlBindParams(
+		"select ts, value from points -- filter on :source\n"+
+			"where name = :metric and note <> 'at :start' and \"col:end\" = 1 /* :origin */ and ts > :start",
+		sqlBindDollar,
+	)
+	assert.Equal(
+		t,
+		"select ts, value from points -- filter on :source\n"+
+			"where name = $1 and note <> 'at :start' and \"col:end\" = 1 /* :origin */ and ts > $2",
+		query,
+	)
+	assert.Equal(t, []string{"metric", "start"}, params)
+}
+
+func Test_SQL(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "facette")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dsn := filepath.Join(tmpDir, "metrics.db")
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %s", err)
+	}
+	defer db.Close()
+
+	for _, stmt := range []string{
+		"create table queues (host text, name text, ts integer, depth real)",
+		"insert into queues values ('mq1', 'orders', 1500000000, 12)",
+		"insert into queues values ('mq1', 'orders', 1500000060, 17.5)",
+		"insert into queues values ('mq1', 'orders', 1500000120, null)",
+		"insert into queues values ('mq1', 'orders', 1500003600, 4)",
+		"insert into queues values ('mq1', 'invoices', 1500000000, 3)",
+		"insert into queues values ('mq2', 'orders', 1500000000, 1)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to initialize database: %s", err)
+		}
+	}
+
+	logger, _ := logger.NewLogger()
+
+	c, err := New("sql", "sql", &maputil.Map{
+		"driver":        "sqlite",
+		"dsn":           dsn,
+		"catalog_query": "select distinct host as source, 'queue.' || name as metric from queues order by 1, 2",
+		"points_query": "select ts, depth from queues where host = :source and 'queue.' || name = :metric " +
+			"and ts >= :start and ts <= :end order by ts",
+		"epoch_time": true,
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to initialize connector: %s", err)
+	}
+
+	// Check catalog records
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 3)
+	assert.Equal(t, catalog.Record{Origin: "sql", Source: "mq1", Metric: "queue.invoices", Attributes: &maputil.Map{
+		"origin": "sql",
+		"source": "mq1",
+		"metric": "queue.invoices",
+	}}, *records[0])
+	assert.Equal(t, "mq2", records[2].Source)
+	assert.Equal(t, "queue.orders", records[2].Metric)
+
+	// Check points
+	cat := catalog.New("sql", c)
+	for _, r := range records {
+		assert.Nil(t, cat.Insert(r))
+	}
+
+	m1, _ := cat.Metric("sql", "mq1", "queue.orders")
+	m2, _ := cat.Metric("sql", "mq2", "queue.orders")
+
+	startTime := time.Unix(1500000000, 0)
+
+	result, err := c.Points(context.Background(), &series.Query{
+		StartTime: startTime,
+		EndTime:   startTime.Add(10 * time.Minute),
+		Sample:    10,
+		Metrics:   []*catalog.Metric{m1, m2},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+
+	expected := series.Series{Points: []series.Point{
+		{Time: startTime, Value: 12},
+		{Time: startTime.Add(time.Minute), Value: 17.5},
+		{Time: startTime.Add(2 * time.Minute), Value: series.Value(math.NaN())},
+	}}
+	if !compareSeries(expected, result[0]) {
+		t.Errorf("Not equal: \nexpected: %#v\nactual  : %#v", expected, result[0])
+	}
+
+	assert.Equal(t, []series.Point{{Time: startTime, Value: 1}}, result[1].Points)
+}
@@ -0,0 +1,50 @@
+package connector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"facette.io/logger"
+)
+
+func walkDir(ctx context.Context, root, originalRoot string, walkFunc filepath.WalkFunc, logger *logger.Logger) error {
+	if _, err := os.Stat(root); err != nil {
+		logger.Error("%s", err)
+		return nil
+	}
+
+	// Walk root directory
+	return filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		var realPath string
+
+		// Stop walking if context is done
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			logger.Error("%s", err)
+			return nil
+		}
+
+		mode := fi.Mode() & os.ModeType
+		if mode == os.ModeSymlink {
+			// Follow symbolic link if evaluation succeeds
+			realPath, err = filepath.EvalSymlinks(path)
+			if err != nil {
+				logger.Error("%s", err)
+				return nil
+			}
+
+			return walkDir(ctx, realPath, path, walkFunc, logger)
+		}
+
+		if originalRoot != "" {
+			path = originalRoot + strings.TrimPrefix(path, root)
+		}
+
+		return walkFunc(path, fi, err)
+	})
+}
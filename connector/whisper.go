@@ -0,0 +1,294 @@
+// +build !disable_connector_whisper
+
+package connector
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/facette/series"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/pkg/errors"
+)
+
+const (
+	whisperMetadataSize    = 16
+	whisperArchiveInfoSize = 12
+	whisperPointSize       = 12
+)
+
+func init() {
+	connectors["whisper"] = func(name string, settings *maputil.Map, logger *logger.Logger) (Connector, error) {
+		var (
+			pattern string
+			err     error
+		)
+
+		c := &whisperConnector{
+			name:   name,
+			logger: logger,
+		}
+
+		// Get connector handler settings
+		c.path, err = settings.GetString("path", ".")
+		if err != nil {
+			return nil, err
+		}
+		c.path = strings.TrimRight(c.path, "/")
+
+		pattern, err = settings.GetString("pattern", "")
+		if err != nil {
+			return nil, err
+		} else if pattern == "" {
+			return nil, ErrMissingConnectorSetting("pattern")
+		}
+
+		// Check and compile regexp pattern
+		c.pattern, err = compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		return c, nil
+	}
+}
+
+// whisperConnector represents a Whisper connector instance.
+type whisperConnector struct {
+	name    string
+	path    string
+	pattern *regexp.Regexp
+	logger  *logger.Logger
+}
+
+func (c *whisperConnector) Name() string {
+	return c.name
+}
+
+func (c *whisperConnector) Points(ctx context.Context, q *series.Query) ([]series.Series, error) {
+	if len(q.Metrics) == 0 {
+		return nil, fmt.Errorf("requested metrics list is empty")
+	}
+
+	now := time.Now()
+
+	result := make([]series.Series, len(q.Metrics))
+	for i, m := range q.Metrics {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		path, err := m.Attributes.GetString("path", "")
+		if err != nil || path == "" {
+			return nil, errors.Wrap(ErrInvalidAttribute, "path")
+		}
+
+		result[i], err = whisperFetch(path, q.StartTime, q.EndTime, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch points from %q: %s", path, err)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *whisperConnector) Refresh(ctx context.Context, output chan<- *catalog.Record) error {
+	// Search for files and parse their path for source/metric pairs
+	walkFunc := func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			c.logger.Error("%s", err)
+			return nil
+		}
+
+		// Skip non-files and non-Whisper files
+		mode := fi.Mode() & os.ModeType
+		if mode != 0 || !strings.HasSuffix(path, ".wsp") {
+			return nil
+		}
+
+		// Get matching pattern elements
+		m, err := matchPattern(c.pattern, strings.TrimPrefix(path, c.path+"/"))
+		if err != nil {
+			c.logger.Warning("%s", err)
+			return nil
+		}
+
+		output <- &catalog.Record{
+			Origin: c.name,
+			Source: m[0],
+			Metric: m[1],
+			Attributes: &maputil.Map{
+				"path": path,
+			},
+		}
+
+		return nil
+	}
+
+	return walkDir(ctx, c.path, "", walkFunc, c.logger)
+}
+
+type whisperHeader struct {
+	aggregation  uint32
+	maxRetention uint32
+	xFilesFactor float32
+	archives     []whisperArchive
+}
+
+type whisperArchive struct {
+	offset          uint32
+	secondsPerPoint uint32
+	points          uint32
+}
+
+func (a whisperArchive) retention() int64 {
+	return int64(a.secondsPerPoint) * int64(a.points)
+}
+
+func whisperReadHeader(f *os.File) (*whisperHeader, error) {
+	buf := make([]byte, whisperMetadataSize)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		return nil, fmt.Errorf("unable to read metadata: %s", err)
+	}
+
+	h := &whisperHeader{
+		aggregation:  binary.BigEndian.Uint32(buf[0:4]),
+		maxRetention: binary.BigEndian.Uint32(buf[4:8]),
+		xFilesFactor: math.Float32frombits(binary.BigEndian.Uint32(buf[8:12])),
+	}
+
+	count := binary.BigEndian.Uint32(buf[12:16])
+	if count == 0 {
+		return nil, fmt.Errorf("no archive found")
+	}
+
+	buf = make([]byte, int(count)*whisperArchiveInfoSize)
+	if _, err := f.ReadAt(buf, whisperMetadataSize); err != nil {
+		return nil, fmt.Errorf("unable to read archives information: %s", err)
+	}
+
+	h.archives = make([]whisperArchive, count)
+	for i := range h.archives {
+		chunk := buf[i*whisperArchiveInfoSize:]
+
+		h.archives[i] = whisperArchive{
+			offset:          binary.BigEndian.Uint32(chunk[0:4]),
+			secondsPerPoint: binary.BigEndian.Uint32(chunk[4:8]),
+			points:          binary.BigEndian.Uint32(chunk[8:12]),
+		}
+
+		if h.archives[i].secondsPerPoint == 0 || h.archives[i].points == 0 {
+			return nil, fmt.Errorf("invalid archive #%d", i)
+		}
+	}
+
+	return h, nil
+}
+
+func whisperFetch(path string, startTime, endTime, now time.Time) (series.Series, error) {
+	result := series.Series{}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer f.Close()
+
+	h, err := whisperReadHeader(f)
+	if err != nil {
+		return result, err
+	}
+
+	from, until, current := startTime.Unix(), endTime.Unix(), now.Unix()
+
+	// Stop if requested range is out of the file retention bounds
+	oldest := current - int64(h.maxRetention)
+	if from > current || until < oldest {
+		return result, nil
+	}
+
+	if from < oldest {
+		from = oldest
+	}
+	if until > current {
+		until = current
+	}
+
+	// Select the highest precision archive covering the requested range, archives being sorted by decreasing
+	// precision (fallback to the lowest precision one)
+	archive := h.archives[len(h.archives)-1]
+	for _, a := range h.archives {
+		if a.retention() >= current-from {
+			archive = a
+			break
+		}
+	}
+
+	step := int64(archive.secondsPerPoint)
+
+	fromInterval := from - from%step + step
+	untilInterval := until - until%step + step
+	if fromInterval == untilInterval {
+		untilInterval += step
+	}
+
+	count := (untilInterval - fromInterval) / step
+	if count > int64(archive.points) {
+		count = int64(archive.points)
+	}
+
+	result.Points = make([]series.Point, count)
+	for i := range result.Points {
+		result.Points[i] = series.Point{
+			Time:  time.Unix(fromInterval+int64(i)*step, 0),
+			Value: series.Value(math.NaN()),
+		}
+	}
+
+	// Read archive base interval, a null value meaning that the archive has never been written
+	buf := make([]byte, whisperPointSize)
+	if _, err := f.ReadAt(buf, int64(archive.offset)); err != nil {
+		return result, fmt.Errorf("unable to read archive: %s", err)
+	}
+
+	base := int64(binary.BigEndian.Uint32(buf[0:4]))
+	if base == 0 {
+		return result, nil
+	}
+
+	// Read points, wrapping around the end of the circular archive if needed
+	n := int64(archive.points)
+	idx := ((fromInterval-base)/step%n + n) % n
+
+	buf = make([]byte, count*whisperPointSize)
+	if idx+count <= n {
+		_, err = f.ReadAt(buf, int64(archive.offset)+idx*whisperPointSize)
+	} else {
+		split := (n - idx) * whisperPointSize
+		if _, err = f.ReadAt(buf[:split], int64(archive.offset)+idx*whisperPointSize); err == nil {
+			_, err = f.ReadAt(buf[split:], int64(archive.offset))
+		}
+	}
+	if err != nil {
+		return result, fmt.Errorf("unable to read archive points: %s", err)
+	}
+
+	// Only keep points matching their expected interval, others being stale data from previous archive cycles
+	for i := range result.Points {
+		chunk := buf[i*whisperPointSize:]
+
+		if int64(binary.BigEndian.Uint32(chunk[0:4])) == fromInterval+int64(i)*step {
+			result.Points[i].Value = series.Value(math.Float64frombits(binary.BigEndian.Uint64(chunk[4:12])))
+		}
+	}
+
+	return result, nil
+}
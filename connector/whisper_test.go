@@ -0,0 +1,153 @@
+// +build !disable_connector_whisper
+
+package connector
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"facette.io/facette/catalog"
+	"facette.io/logger"
+	"facette.io/maputil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Whisper_Refresh(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "facette")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, path := range []string{"host1/cpu/idle.wsp", "host1/load.wsp", "host2/load.wsp", "host2/README"} {
+		path = filepath.Join(tmpDir, path)
+
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, nil, 0644))
+	}
+
+	logger, _ := logger.NewLogger()
+
+	c, err := New("whisper", "whisper", &maputil.Map{
+		"path":    tmpDir,
+		"pattern": `(?P<source>[^/]+)/(?P<metric>.+)\.wsp`,
+	}, logger)
+	if err != nil {
+		t.Fatalf("failed to initialize connector: %s", err)
+	}
+
+	output := make(chan *catalog.Record)
+	records := []*catalog.Record{}
+
+	go func() {
+		assert.Nil(t, c.Refresh(context.Background(), output))
+		close(output)
+	}()
+
+	for record := range output {
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 3)
+	assert.Equal(t, catalog.Record{Origin: "whisper", Source: "host1", Metric: "cpu/idle", Attributes: &maputil.Map{
+		"path": filepath.Join(tmpDir, "host1/cpu/idle.wsp"),
+	}}, *records[0])
+	assert.Equal(t, "host1", records[1].Source)
+	assert.Equal(t, "load", records[1].Metric)
+	assert.Equal(t, "host2", records[2].Source)
+	assert.Equal(t, "load", records[2].Metric)
+}
+
+func Test_Whisper_Fetch(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "facette")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "load.wsp")
+	now := time.Unix(1500000600, 0)
+
+	// Create a file with a 1m:10m archive and a 5m:1h one, filling the first archive with values 1 to 10 written
+	// such as the circular buffer has wrapped around
+	points := [][2]float64{}
+	for i := 0; i < 10; i++ {
+		points = append(points, [2]float64{float64(1500000060 + i*60), float64(i + 1)})
+	}
+	testWhisperCreate(path, [][2]uint32{{60, 10}, {300, 12}}, points[6:], points[:6], t)
+
+	s, err := whisperFetch(path, now.Add(-5*time.Minute), now, now)
+	assert.Nil(t, err)
+	assert.Len(t, s.Points, 5)
+
+	for i, p := range s.Points {
+		assert.Equal(t, time.Unix(1500000360+int64(i)*60, 0), p.Time)
+		assert.Equal(t, float64(i+6), float64(p.Value))
+	}
+
+	// Check for lower precision archive selection (never written, thus only holding null values)
+	s, err = whisperFetch(path, now.Add(-50*time.Minute), now, now)
+	assert.Nil(t, err)
+	assert.Len(t, s.Points, 10)
+	assert.Equal(t, time.Unix(1499997900, 0), s.Points[0].Time)
+
+	for _, p := range s.Points {
+		assert.True(t, p.Value.IsNaN())
+	}
+
+	// Check for out of retention range
+	s, err = whisperFetch(path, now.Add(-48*time.Hour), now.Add(-24*time.Hour), now)
+	assert.Nil(t, err)
+	assert.Len(t, s.Points, 0)
+}
+
+func testWhisperCreate(path string, archives [][2]uint32, head, tail [][2]float64, t *testing.T) {
+	size := whisperMetadataSize + len(archives)*whisperArchiveInfoSize
+	for _, a := range archives {
+		size += int(a[1]) * whisperPointSize
+	}
+
+	buf := make([]byte, size)
+
+	maxRetention := uint32(0)
+	for _, a := range archives {
+		if r := a[0] * a[1]; r > maxRetention {
+			maxRetention = r
+		}
+	}
+
+	binary.BigEndian.PutUint32(buf[0:4], 1)
+	binary.BigEndian.PutUint32(buf[4:8], maxRetention)
+	binary.BigEndian.PutUint32(buf[8:12], math.Float32bits(0.5))
+	binary.BigEndian.PutUint32(buf[12:16], uint32(len(archives)))
+
+	offset := whisperMetadataSize + len(archives)*whisperArchiveInfoSize
+	offsets := []int{}
+
+	for i, a := range archives {
+		chunk := buf[whisperMetadataSize+i*whisperArchiveInfoSize:]
+		binary.BigEndian.PutUint32(chunk[0:4], uint32(offset))
+		binary.BigEndian.PutUint32(chunk[4:8], a[0])
+		binary.BigEndian.PutUint32(chunk[8:12], a[1])
+
+		offsets = append(offsets, offset)
+		offset += int(a[1]) * whisperPointSize
+	}
+
+	// Write points in the first archive, the head part being located at its beginning
+	for i, p := range append(head, tail...) {
+		chunk := buf[offsets[0]+i*whisperPointSize:]
+		binary.BigEndian.PutUint32(chunk[0:4], uint32(p[0]))
+		binary.BigEndian.PutUint64(chunk[4:12], math.Float64bits(p[1]))
+	}
+
+	if err := ioutil.WriteFile(path, buf, 0644); err != nil {
+		t.Fatalf("failed to create file: %s", err)
+	}
+}
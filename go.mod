@@ -13,20 +13,24 @@ require (
 	github.com/cosiner/argv v0.0.0-20170225145430-13bacc38a0a5 // indirect
 	github.com/cosiner/flag v0.1.1
 	github.com/erikstmartin/go-testdb v0.0.0-20160219214506-8d10e4a1bae5 // indirect
+	github.com/go-sql-driver/mysql v1.4.1
 	github.com/google/go-cmp v0.2.0 // indirect
 	github.com/hashicorp/go-uuid v0.0.0-20180228145832-27454136f036
 	github.com/influxdata/influxdb v1.6.1
 	github.com/influxdata/influxql v0.0.0-20180717201005-c661ab7db8ad
 	github.com/jinzhu/gorm v1.9.10
 	github.com/kr/pretty v0.1.0 // indirect
+	github.com/lib/pq v1.1.1
 	github.com/mattn/go-colorable v0.0.9 // indirect
 	github.com/mattn/go-isatty v0.0.3 // indirect
+	github.com/mattn/go-sqlite3 v1.10.0
 	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b
 	github.com/oklog/run v1.0.0
 	github.com/pkg/errors v0.8.0
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/testify v1.2.2
 	github.com/vbatoufflet/httprouter v0.0.0-20181205060457-8c71425e86c8
+	github.com/wcharczuk/go-chart/v2 v2.1.0
 	github.com/ziutek/rrd v0.0.0-20170125160940-86f4f09e7abb
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v2 v2.2.2
@@ -0,0 +1,8 @@
+package grafana
+
+import "errors"
+
+var (
+	// ErrInvalidOperator represents an invalid filter operator error.
+	ErrInvalidOperator = errors.New("invalid filter operator")
+)
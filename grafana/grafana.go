@@ -0,0 +1,121 @@
+// Package grafana implements the Grafana JSON datasource protocol types.
+package grafana
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"facette.io/facette/series"
+)
+
+// Filters operators
+const (
+	OperatorEqual       = "="
+	OperatorNotEqual    = "!="
+	OperatorMatch       = "=~"
+	OperatorNotMatch    = "!~"
+	OperatorLessThan    = "<"
+	OperatorGreaterThan = ">"
+)
+
+// QueryRequest represents a query request instance.
+type QueryRequest struct {
+	Range         Range    `json:"range"`
+	Targets       []Target `json:"targets"`
+	MaxDataPoints int      `json:"maxDataPoints"`
+	AdhocFilters  []Filter `json:"adhocFilters"`
+}
+
+// Range represents a query request time range instance.
+type Range struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Target represents a query request target instance.
+type Target struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	Type   string `json:"type"`
+	Hide   bool   `json:"hide"`
+}
+
+// Filter represents a query request ad hoc filter instance.
+type Filter struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// Match returns whether or not a value satisfies the filter, or an error if the filter is invalid.
+func (f Filter) Match(value string) (bool, error) {
+	switch f.Operator {
+	case OperatorEqual:
+		return value == f.Value, nil
+
+	case OperatorNotEqual:
+		return value != f.Value, nil
+
+	case OperatorMatch, OperatorNotMatch:
+		re, err := regexp.Compile(f.Value)
+		if err != nil {
+			return false, err
+		}
+
+		return re.MatchString(value) == (f.Operator == OperatorMatch), nil
+
+	case OperatorLessThan:
+		return value < f.Value, nil
+
+	case OperatorGreaterThan:
+		return value > f.Value, nil
+	}
+
+	return false, ErrInvalidOperator
+}
+
+// SearchRequest represents a search request instance.
+type SearchRequest struct {
+	Target string `json:"target"`
+}
+
+// SearchResult represents a search response entry instance.
+type SearchResult struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// Series represents a query response time series instance.
+type Series struct {
+	Target     string      `json:"target"`
+	RefID      string      `json:"refId,omitempty"`
+	Datapoints []Datapoint `json:"datapoints"`
+}
+
+// Datapoint represents a query response time series data point instance.
+type Datapoint struct {
+	Time  time.Time
+	Value series.Value
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Datapoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]interface{}{d.Value, d.Time.UnixNano() / int64(time.Millisecond)})
+}
+
+// TagKey represents a tag keys response entry instance.
+type TagKey struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// TagValuesRequest represents a tag values request instance.
+type TagValuesRequest struct {
+	Key string `json:"key"`
+}
+
+// TagValue represents a tag values response entry instance.
+type TagValue struct {
+	Text string `json:"text"`
+}
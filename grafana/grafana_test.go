@@ -0,0 +1,54 @@
+package grafana
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"facette.io/facette/series"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Filter_Match(t *testing.T) {
+	for _, entry := range []struct {
+		filter   Filter
+		value    string
+		expected bool
+	}{
+		{Filter{Operator: OperatorEqual, Value: "host1"}, "host1", true},
+		{Filter{Operator: OperatorEqual, Value: "host1"}, "host2", false},
+		{Filter{Operator: OperatorNotEqual, Value: "host1"}, "host2", true},
+		{Filter{Operator: OperatorMatch, Value: "^host[0-9]$"}, "host1", true},
+		{Filter{Operator: OperatorMatch, Value: "^host[0-9]$"}, "db1", false},
+		{Filter{Operator: OperatorNotMatch, Value: "^host"}, "db1", true},
+		{Filter{Operator: OperatorLessThan, Value: "b"}, "a", true},
+		{Filter{Operator: OperatorGreaterThan, Value: "b"}, "a", false},
+	} {
+		actual, err := entry.filter.Match(entry.value)
+		assert.Nil(t, err)
+		assert.Equal(t, entry.expected, actual, entry.filter)
+	}
+}
+
+func Test_Filter_Match_Invalid(t *testing.T) {
+	_, err := Filter{Operator: "<>", Value: "a"}.Match("a")
+	assert.Equal(t, ErrInvalidOperator, err)
+
+	_, err = Filter{Operator: OperatorMatch, Value: "[a"}.Match("a")
+	assert.NotNil(t, err)
+}
+
+func Test_Series_Marshal(t *testing.T) {
+	data, err := json.Marshal(Series{
+		Target: "host1.cpu.idle",
+		RefID:  "A",
+		Datapoints: []Datapoint{
+			{Time: time.Unix(1496838488, 0), Value: 12.5},
+			{Time: time.Unix(1496838548, 0), Value: series.Value(math.NaN())},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"target":"host1.cpu.idle","refId":"A","datapoints":[[12.5,1496838488000],[null,1496838548000]]}`,
+		string(data))
+}
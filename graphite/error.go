@@ -0,0 +1,8 @@
+package graphite
+
+import "errors"
+
+var (
+	// ErrInvalidTime represents an invalid time specification error.
+	ErrInvalidTime = errors.New("invalid time specification")
+)
@@ -0,0 +1,216 @@
+// Package graphite implements helpers for the Graphite render API compatibility layer, mapping catalog entries onto
+// dotted metric paths and parsing Graphite time specifications.
+package graphite
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"facette.io/facette/series"
+)
+
+var offsetRegexp = regexp.MustCompile(`^([-+])(\d+)\s*([a-z]+)$`)
+
+// Node represents a metrics find response node instance.
+type Node struct {
+	ID            string `json:"id"`
+	Text          string `json:"text"`
+	Leaf          int    `json:"leaf"`
+	Expandable    int    `json:"expandable"`
+	AllowChildren int    `json:"allowChildren"`
+}
+
+// NewNode creates a new metrics find response node instance.
+func NewNode(id string, leaf bool) Node {
+	node := Node{ID: id, Text: id[strings.LastIndex(id, ".")+1:]}
+	if leaf {
+		node.Leaf = 1
+	} else {
+		node.Expandable = 1
+		node.AllowChildren = 1
+	}
+
+	return node
+}
+
+// Series represents a render response series instance.
+type Series struct {
+	Target     string      `json:"target"`
+	Datapoints []Datapoint `json:"datapoints"`
+}
+
+// Datapoint represents a render response series data point instance.
+type Datapoint struct {
+	Time  time.Time
+	Value series.Value
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Datapoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]interface{}{d.Value, d.Time.Unix()})
+}
+
+// Escape escapes a catalog entry name for use as a metric path node, replacing dots with underscores. As a result,
+// names only differing by dots and underscores (e.g. "a.b" and "a_b") are mapped onto the same node.
+func Escape(name string) string {
+	return strings.Replace(name, ".", "_", -1)
+}
+
+// Split splits a metric path into its nodes, ignoring dots located in braces alternatives.
+func Split(p string) []string {
+	result := []string{}
+
+	depth, start := 0, 0
+	for i, r := range p {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case '.':
+			if depth == 0 {
+				result = append(result, p[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(result, p[start:])
+}
+
+// Match returns whether or not a metric path node matches a pattern node. Patterns support the "*", "?" and "[...]"
+// wildcards, along with "{a,b}" alternatives.
+func Match(pattern, node string) bool {
+	// Remove slashes from pattern and node as 'path.Match' does not handle them
+	node = strings.Replace(node, "/", "\x1e", -1)
+
+	for _, p := range expand(pattern) {
+		if ok, _ := path.Match(strings.Replace(p, "/", "\x1e", -1), node); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseTime parses a Graphite time specification relative to a reference time. Supported specifications are "now",
+// Unix timestamps, relative offsets (e.g. "-1h", "-30min", "now-7d"), "HH:MM_YYYYMMDD" and "YYYYMMDD".
+func ParseTime(s string, ref time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	switch {
+	case s == "" || s == "now":
+		return ref, nil
+
+	case strings.HasPrefix(s, "now"):
+		return applyOffset(ref, strings.TrimPrefix(s, "now"))
+
+	case strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+"):
+		return applyOffset(ref, s)
+
+	case isDigits(s) && !isDate(s):
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, ErrInvalidTime
+		}
+
+		return time.Unix(v, 0).UTC(), nil
+	}
+
+	for _, layout := range []string{"15:04_20060102", "20060102"} {
+		if t, err := time.ParseInLocation(layout, s, ref.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, ErrInvalidTime
+}
+
+func applyOffset(ref time.Time, s string) (time.Time, error) {
+	m := offsetRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, ErrInvalidTime
+	}
+
+	v, err := strconv.Atoi(m[2])
+	if err != nil {
+		return time.Time{}, ErrInvalidTime
+	}
+
+	if m[1] == "-" {
+		v = -v
+	}
+
+	var unit time.Duration
+
+	switch {
+	case strings.HasPrefix(m[3], "s"):
+		unit = time.Second
+	case strings.HasPrefix(m[3], "min"):
+		unit = time.Minute
+	case strings.HasPrefix(m[3], "h"):
+		unit = time.Hour
+	case strings.HasPrefix(m[3], "d"):
+		unit = 24 * time.Hour
+	case strings.HasPrefix(m[3], "w"):
+		unit = 7 * 24 * time.Hour
+	case strings.HasPrefix(m[3], "mon"):
+		unit = 30 * 24 * time.Hour
+	case strings.HasPrefix(m[3], "y"):
+		unit = 365 * 24 * time.Hour
+	default:
+		return time.Time{}, ErrInvalidTime
+	}
+
+	return ref.Add(time.Duration(v) * unit), nil
+}
+
+// expand expands the "{a,b}" alternatives of a pattern.
+func expand(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start == -1 {
+		return []string{pattern}
+	}
+
+	end := strings.Index(pattern[start:], "}")
+	if end == -1 {
+		return []string{pattern}
+	}
+	end += start
+
+	result := []string{}
+	for _, alt := range strings.Split(pattern[start+1:end], ",") {
+		result = append(result, expand(pattern[:start]+alt+pattern[end+1:])...)
+	}
+
+	return result
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDate returns whether or not a digits string is to be parsed as a YYYYMMDD date rather than a Unix timestamp.
+func isDate(s string) bool {
+	if len(s) != 8 {
+		return false
+	}
+
+	year, _ := strconv.Atoi(s[:4])
+	month, _ := strconv.Atoi(s[4:6])
+	day, _ := strconv.Atoi(s[6:])
+
+	return year > 1900 && year < 2100 && month >= 1 && month <= 12 && day >= 1 && day <= 31
+}
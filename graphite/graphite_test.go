@@ -0,0 +1,89 @@
+package graphite
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"facette.io/facette/series"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Escape(t *testing.T) {
+	assert.Equal(t, "host1_example_net", Escape("host1.example.net"))
+	assert.Equal(t, "cpu-0/user", Escape("cpu-0/user"))
+}
+
+func Test_Split(t *testing.T) {
+	assert.Equal(t, []string{"a"}, Split("a"))
+	assert.Equal(t, []string{"a", "b*", "c"}, Split("a.b*.c"))
+	assert.Equal(t, []string{"a", "{b.x,c}", "d"}, Split("a.{b.x,c}.d"))
+	assert.Equal(t, []string{"a", ""}, Split("a."))
+}
+
+func Test_Match(t *testing.T) {
+	assert.True(t, Match("host1_example_net", "host1_example_net"))
+	assert.True(t, Match("host*", "host1_example_net"))
+	assert.True(t, Match("host?_example_net", "host1_example_net"))
+	assert.True(t, Match("host[0-9]*", "host1_example_net"))
+	assert.True(t, Match("{web,host}1*", "host1_example_net"))
+	assert.True(t, Match("cpu-0/*", "cpu-0/user"))
+	assert.False(t, Match("web*", "host1_example_net"))
+	assert.False(t, Match("{web,db}1*", "host1_example_net"))
+	assert.False(t, Match("host[", "host1_example_net"))
+}
+
+func Test_ParseTime(t *testing.T) {
+	ref := time.Date(2017, 6, 7, 12, 30, 0, 0, time.UTC)
+
+	for _, entry := range []struct {
+		input    string
+		expected time.Time
+	}{
+		{"", ref},
+		{"now", ref},
+		{"-1h", ref.Add(-time.Hour)},
+		{"-30min", ref.Add(-30 * time.Minute)},
+		{"-10s", ref.Add(-10 * time.Second)},
+		{"-2d", ref.Add(-48 * time.Hour)},
+		{"-1w", ref.Add(-7 * 24 * time.Hour)},
+		{"-1mon", ref.Add(-30 * 24 * time.Hour)},
+		{"-1y", ref.Add(-365 * 24 * time.Hour)},
+		{"+5minutes", ref.Add(5 * time.Minute)},
+		{"now-6h", ref.Add(-6 * time.Hour)},
+		{"1496838488", time.Unix(1496838488, 0).UTC()},
+		{"20170601", time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"04:00_20170601", time.Date(2017, 6, 1, 4, 0, 0, 0, time.UTC)},
+	} {
+		actual, err := ParseTime(entry.input, ref)
+		assert.Nil(t, err, entry.input)
+		assert.Equal(t, entry.expected, actual, entry.input)
+	}
+}
+
+func Test_ParseTime_Invalid(t *testing.T) {
+	ref := time.Now().UTC()
+
+	for _, input := range []string{"-1m", "-h", "yesterday", "now+", "12:00"} {
+		_, err := ParseTime(input, ref)
+		assert.Equal(t, ErrInvalidTime, err, input)
+	}
+}
+
+func Test_NewNode(t *testing.T) {
+	assert.Equal(t, Node{ID: "host1", Text: "host1", Expandable: 1, AllowChildren: 1}, NewNode("host1", false))
+	assert.Equal(t, Node{ID: "host1.cpu.idle", Text: "idle", Leaf: 1}, NewNode("host1.cpu.idle", true))
+}
+
+func Test_Series_Marshal(t *testing.T) {
+	data, err := json.Marshal(Series{
+		Target: "host1.cpu.idle",
+		Datapoints: []Datapoint{
+			{Time: time.Unix(1496838488, 0), Value: 12.5},
+			{Time: time.Unix(1496838548, 0), Value: series.Value(math.NaN())},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"target":"host1.cpu.idle","datapoints":[[12.5,1496838488],[null,1496838548]]}`, string(data))
+}
@@ -0,0 +1,138 @@
+// Command exec-plugin is a reference plugin for the "exec" connector, generating synthetic wave series.
+//
+// It reads a single JSON request from its standard input and writes JSON messages to its standard output, as
+// described in docs/connector-exec.md. Supported options:
+//
+//   sources: list of source names to generate series for (default: ["host1", "host2"])
+//   period:  waves period in seconds (default: 3600)
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+type request struct {
+	Method  string                 `json:"method"`
+	Name    string                 `json:"name"`
+	Options map[string]interface{} `json:"options"`
+	Query   *query                 `json:"query"`
+}
+
+type query struct {
+	StartTime int64    `json:"start_time"`
+	EndTime   int64    `json:"end_time"`
+	Sample    int      `json:"sample"`
+	Metrics   []record `json:"metrics"`
+}
+
+type record struct {
+	Origin     string                 `json:"origin,omitempty"`
+	Source     string                 `json:"source"`
+	Metric     string                 `json:"metric"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+type series struct {
+	Points [][2]interface{} `json:"points"`
+}
+
+type message struct {
+	Record *record  `json:"record,omitempty"`
+	Series []series `json:"series,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func main() {
+	enc := json.NewEncoder(os.Stdout)
+
+	req := request{}
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		enc.Encode(message{Error: fmt.Sprintf("unable to unmarshal request: %s", err)})
+		os.Exit(1)
+	}
+
+	switch req.Method {
+	case "refresh":
+		refresh(req, enc)
+
+	case "points":
+		if req.Query == nil {
+			enc.Encode(message{Error: "missing query"})
+			return
+		}
+
+		points(req, enc)
+
+	default:
+		enc.Encode(message{Error: fmt.Sprintf("unsupported %q method", req.Method)})
+	}
+}
+
+func refresh(req request, enc *json.Encoder) {
+	sources := []string{"host1", "host2"}
+	if v, ok := req.Options["sources"].([]interface{}); ok {
+		sources = []string{}
+		for _, s := range v {
+			sources = append(sources, fmt.Sprintf("%v", s))
+		}
+	}
+
+	period := 3600.0
+	if v, ok := req.Options["period"].(float64); ok && v > 0 {
+		period = v
+	}
+
+	for _, source := range sources {
+		for _, metric := range []string{"wave.sine", "wave.square"} {
+			enc.Encode(message{Record: &record{
+				Source:     source,
+				Metric:     metric,
+				Attributes: map[string]interface{}{"period": period},
+			}})
+		}
+	}
+}
+
+func points(req request, enc *json.Encoder) {
+	q := req.Query
+
+	step := (q.EndTime - q.StartTime) / int64(q.Sample)
+	if step < 1 {
+		step = 1
+	}
+
+	result := make([]series, len(q.Metrics))
+	for i, m := range q.Metrics {
+		period, ok := m.Attributes["period"].(float64)
+		if !ok || period <= 0 {
+			enc.Encode(message{Error: "invalid period attribute"})
+			return
+		}
+
+		result[i].Points = [][2]interface{}{}
+		for t := q.StartTime - q.StartTime%step; t <= q.EndTime; t += step {
+			var value interface{}
+
+			x := math.Sin(2 * math.Pi * float64(t) / period)
+
+			switch m.Metric {
+			case "wave.sine":
+				value = x
+
+			case "wave.square":
+				if x >= 0 {
+					value = 1
+				} else {
+					value = -1
+				}
+			}
+
+			result[i].Points = append(result[i].Points, [2]interface{}{t, value})
+		}
+	}
+
+	enc.Encode(message{Series: result})
+}
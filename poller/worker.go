@@ -1,6 +1,8 @@
 package poller
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -26,6 +28,8 @@ type worker struct {
 	filters    *catalog.FilterChain
 	refreshing bool
 	cmdChan    chan int
+	ctx        context.Context
+	cancel     context.CancelFunc
 }
 
 func newWorker(poller *Poller, provider *storage.Provider, logger *logger.Logger) (*worker, error) {
@@ -35,6 +39,8 @@ func newWorker(poller *Poller, provider *storage.Provider, logger *logger.Logger
 		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &worker{
 		poller:    poller,
 		logger:    logger,
@@ -42,6 +48,8 @@ func newWorker(poller *Poller, provider *storage.Provider, logger *logger.Logger
 		connector: c,
 		filters:   catalog.NewFilterChain(&provider.Filters),
 		cmdChan:   make(chan int),
+		ctx:       ctx,
+		cancel:    cancel,
 	}, nil
 }
 
@@ -130,8 +138,8 @@ func (w *worker) Run() {
 				go func() {
 					w.refreshing = true
 
-					err := w.connector.Refresh(w.filters.Input)
-					if err != nil {
+					err := w.connector.Refresh(w.ctx, w.filters.Input)
+					if err != nil && w.ctx.Err() == nil {
 						w.logger.Error("provider %q encountered an error: %s", w.provider.Name, err)
 					}
 
@@ -147,6 +155,13 @@ func (w *worker) Run() {
 					ticker.Stop()
 				}
 
+				// Release connector resources if any
+				if closer, ok := w.connector.(io.Closer); ok {
+					if err := closer.Close(); err != nil {
+						w.logger.Warning("failed to close connector: %s", err)
+					}
+				}
+
 				goto stop
 			}
 
@@ -160,6 +175,9 @@ stop:
 }
 
 func (w *worker) Shutdown() {
+	// Cancel pending connector operations
+	w.cancel()
+
 	if w.catalog != nil {
 		// Unregister catalog from searcher instance
 		w.poller.searcher.Unregister(w.catalog)
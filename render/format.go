@@ -0,0 +1,164 @@
+package render
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// UnitFixed represents a fixed Y axis unit.
+	UnitFixed = "fixed"
+	// UnitMetric represents a metric (SI prefixes) Y axis unit.
+	UnitMetric = "metric"
+	// UnitBinary represents a binary (1024-based prefixes) Y axis unit.
+	UnitBinary = "binary"
+	// UnitDuration represents a duration Y axis unit, values base being either "ns", "us", "ms" or "s".
+	UnitDuration = "duration"
+)
+
+var (
+	metricPrefixes   = []string{"p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E"}
+	binaryPrefixes   = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+	durationUnits    = []string{"ns", "µs", "ms", "s"}
+	durationBaseExps = map[string]int{"ns": 0, "us": 1, "µs": 1, "ms": 2, "s": 3}
+
+	timeSteps = []time.Duration{
+		time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+		time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+		time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+		24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour, 365 * 24 * time.Hour,
+	}
+)
+
+// niceTicks returns evenly spaced round tick values covering a values range, along with the resulting range bounds.
+func niceTicks(min, max float64, count int) ([]float64, float64, float64) {
+	if count < 2 {
+		count = 2
+	}
+
+	if math.IsNaN(min) || math.IsNaN(max) {
+		min, max = 0, 1
+	} else if max-min <= math.Abs(min)*1e-9 {
+		// Widen empty or too narrow ranges, ticks not being distinguishable given float64 precision
+		if min == 0 {
+			max = 1
+		} else {
+			min, max = min-math.Abs(min)/2, max+math.Abs(max)/2
+		}
+	}
+
+	step := niceNumber((max - min) / float64(count-1))
+	min = math.Floor(min/step) * step
+	max = math.Ceil(max/step) * step
+
+	first := math.Round(min / step)
+	n := int(math.Round(max/step - first))
+	if n > 2*count {
+		n = 2 * count
+	}
+
+	result := []float64{}
+	for i := 0; i <= n; i++ {
+		// Prevent floating point rounding errors on fractional steps (e.g. 3*0.1 giving 0.30000000000000004)
+		if step < 1 {
+			result = append(result, (first+float64(i))/math.Round(1/step))
+		} else {
+			result = append(result, (first+float64(i))*step)
+		}
+	}
+
+	return result, min, max
+}
+
+// niceNumber returns a round number (i.e. 1, 2, 5 or 10 multiplied by a power of 10) approximately equal to a value.
+func niceNumber(v float64) float64 {
+	exp := math.Floor(math.Log10(v))
+	fraction := v / math.Pow(10, exp)
+
+	switch {
+	case fraction < 1.5:
+		fraction = 1
+	case fraction < 3:
+		fraction = 2
+	case fraction < 7:
+		fraction = 5
+	default:
+		fraction = 10
+	}
+
+	return fraction * math.Pow(10, exp)
+}
+
+// timeTicks returns round time ticks covering a time span, no more than count ticks being returned, along with the
+// time layout suitable for formatting them.
+func timeTicks(start, end time.Time, count int) ([]time.Time, string) {
+	if count < 1 {
+		count = 1
+	}
+
+	span := end.Sub(start)
+
+	step := timeSteps[len(timeSteps)-1]
+	for _, s := range timeSteps {
+		if span/s <= time.Duration(count) {
+			step = s
+			break
+		}
+	}
+
+	layout := "15:04"
+	if step < time.Minute {
+		layout = "15:04:05"
+	} else if step >= 24*time.Hour {
+		layout = "Jan 02"
+	} else if span > 24*time.Hour {
+		layout = "Jan 02 15:04"
+	}
+
+	result := []time.Time{}
+	for t := start.UTC().Truncate(step); !t.After(end); t = t.Add(step) {
+		if !t.Before(start) {
+			result = append(result, t)
+		}
+	}
+
+	return result, layout
+}
+
+// formatValue formats a Y axis value given its unit.
+func formatValue(v float64, unit, base string) string {
+	if v == 0 {
+		return "0"
+	}
+
+	switch unit {
+	case UnitMetric:
+		idx := int(math.Floor(math.Log10(math.Abs(v)) / 3))
+		idx = int(math.Max(-4, math.Min(float64(idx), float64(len(metricPrefixes)-5))))
+		return formatFloat(v/math.Pow(1000, float64(idx))) + metricPrefixes[idx+4]
+
+	case UnitBinary:
+		idx := int(math.Floor(math.Log(math.Abs(v)) / math.Log(1024)))
+		idx = int(math.Max(0, math.Min(float64(idx), float64(len(binaryPrefixes)-1))))
+		return formatFloat(v/math.Pow(1024, float64(idx))) + binaryPrefixes[idx]
+
+	case UnitDuration:
+		exp, ok := durationBaseExps[base]
+		if !ok {
+			exp = durationBaseExps["ms"]
+		}
+
+		v *= math.Pow(1000, float64(exp))
+
+		idx := int(math.Floor(math.Log10(math.Abs(v)) / 3))
+		idx = int(math.Max(0, math.Min(float64(idx), float64(len(durationUnits)-1))))
+		return formatFloat(v/math.Pow(1000, float64(idx))) + durationUnits[idx]
+	}
+
+	return formatFloat(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,360 @@
+// Package render implements server-side graph rendering, producing either SVG or PNG images.
+package render
+
+import (
+	"html"
+	"image/color"
+	"io"
+	"math"
+	"time"
+
+	"facette.io/facette/series"
+	chart "github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+const (
+	// TypeLine represents a line graph type.
+	TypeLine = "line"
+	// TypeArea represents an area graph type.
+	TypeArea = "area"
+	// TypeStacked represents a stacked areas graph type.
+	TypeStacked = "stacked"
+)
+
+const (
+	// DefaultWidth represents the default rendered graph width.
+	DefaultWidth = 800
+	// DefaultHeight represents the default rendered graph height.
+	DefaultHeight = 400
+)
+
+const (
+	padding       = 10
+	titlePadding  = 30
+	titleFontSize = 12.0
+	areaFillAlpha = 0x4c
+	xTicksSpacing = 100
+)
+
+var (
+	colorText = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	colorAxis = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	colorGrid = color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+
+	palette = []string{
+		"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+		"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+	}
+)
+
+// Graph represents a graph rendering definition instance.
+type Graph struct {
+	Title     string
+	Type      string
+	Unit      string
+	UnitBase  string
+	Width     int
+	Height    int
+	StartTime time.Time
+	EndTime   time.Time
+	Series    []Series
+}
+
+// Series represents a graph rendering series instance.
+type Series struct {
+	Name   string
+	Color  string
+	Points []series.Point
+}
+
+// SVG renders a graph as a SVG image.
+func SVG(w io.Writer, g Graph) error {
+	return g.chart().Render(newSVGRenderer, w)
+}
+
+// PNG renders a graph as a PNG image.
+func PNG(w io.Writer, g Graph) error {
+	return g.chart().Render(chart.PNG, w)
+}
+
+// svgRenderer represents a SVG renderer escaping texts, as the underlying renderer writes them verbatim.
+type svgRenderer struct {
+	chart.Renderer
+}
+
+func newSVGRenderer(width, height int) (chart.Renderer, error) {
+	r, err := chart.SVG(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return svgRenderer{r}, nil
+}
+
+func (r svgRenderer) Text(body string, x, y int) {
+	r.Renderer.Text(html.EscapeString(body), x, y)
+}
+
+func (g Graph) width() int {
+	if g.Width <= 0 {
+		return DefaultWidth
+	}
+	return g.Width
+}
+
+func (g Graph) height() int {
+	if g.Height <= 0 {
+		return DefaultHeight
+	}
+	return g.Height
+}
+
+// chart returns the chart definition of the graph.
+func (g Graph) chart() chart.Chart {
+	start, end := g.StartTime, g.EndTime
+	if !end.After(start) {
+		end = start.Add(time.Second)
+	}
+
+	values, min, max := g.values()
+
+	// Compute axes ticks, the X axis range being set by its extreme ticks
+	yTicks, yMin, yMax := niceTicks(min, max, 5)
+
+	yAxisTicks := make([]chart.Tick, len(yTicks))
+	for i, v := range yTicks {
+		yAxisTicks[i] = chart.Tick{Value: v, Label: formatValue(v, g.Unit, g.UnitBase)}
+	}
+
+	xTicks, layout := timeTicks(start, end, g.width()/xTicksSpacing)
+
+	xAxisTicks := []chart.Tick{}
+	for _, t := range xTicks {
+		if !t.Before(start) && !t.After(end) {
+			xAxisTicks = append(xAxisTicks, chart.Tick{Value: chart.TimeToFloat64(t), Label: t.Format(layout)})
+		}
+	}
+
+	if len(xAxisTicks) == 0 || xAxisTicks[0].Value > chart.TimeToFloat64(start) {
+		xAxisTicks = append([]chart.Tick{{Value: chart.TimeToFloat64(start)}}, xAxisTicks...)
+	}
+	if xAxisTicks[len(xAxisTicks)-1].Value < chart.TimeToFloat64(end) {
+		xAxisTicks = append(xAxisTicks, chart.Tick{Value: chart.TimeToFloat64(end)})
+	}
+
+	axisStyle := chart.Style{
+		StrokeColor: drawingColor(colorAxis),
+		FontColor:   drawingColor(colorText),
+	}
+
+	c := chart.Chart{
+		Title:      g.Title,
+		TitleStyle: chart.Style{FontSize: titleFontSize, FontColor: drawingColor(colorText)},
+		Width:      g.width(),
+		Height:     g.height(),
+		Background: chart.Style{
+			Padding: chart.Box{Top: padding, Left: padding, Right: padding, Bottom: padding},
+		},
+		XAxis: chart.XAxis{
+			Style: axisStyle,
+			Range: &chart.ContinuousRange{},
+			Ticks: xAxisTicks,
+		},
+		YAxis: chart.YAxis{
+			// Draw Y axis on the left side of the graph
+			AxisType:       chart.YAxisSecondary,
+			Style:          axisStyle,
+			Zero:           chart.GridLine{Style: chart.Hidden()},
+			Range:          &chart.ContinuousRange{Min: yMin, Max: yMax},
+			Ticks:          yAxisTicks,
+			GridMajorStyle: chart.Style{StrokeColor: drawingColor(colorGrid), StrokeWidth: 1},
+		},
+	}
+
+	if g.Title != "" {
+		c.Background.Padding.Top = titlePadding
+	}
+
+	// Append series segments, stacked series being drawn from top to bottom as their areas fill down to the baseline
+	legend := &chart.Chart{}
+
+	for i, s := range g.Series {
+		col := seriesColor(s.Color, i)
+
+		style := chart.Style{StrokeColor: drawingColor(col), StrokeWidth: 1}
+		legend.Series = append(legend.Series, chart.TimeSeries{Name: s.Name, Style: style})
+
+		switch g.Type {
+		case TypeArea:
+			style.FillColor = drawingColor(col).WithAlpha(areaFillAlpha)
+		case TypeStacked:
+			style.FillColor = opaqueFillColor(col)
+		}
+
+		var chartSeries []chart.Series
+		for _, segment := range segments(s.Points, values[i]) {
+			ts := chart.TimeSeries{Name: s.Name, Style: style}
+			for _, idx := range segment {
+				ts.XValues = append(ts.XValues, s.Points[idx].Time)
+				ts.YValues = append(ts.YValues, values[i][idx])
+			}
+
+			chartSeries = append(chartSeries, ts)
+		}
+
+		if g.Type == TypeStacked {
+			c.Series = append(chartSeries, c.Series...)
+		} else {
+			c.Series = append(c.Series, chartSeries...)
+		}
+	}
+
+	if math.IsNaN(min) {
+		// Draw a placeholder series over the X axis, as rendering requires at least one visible series
+		c.Series = []chart.Series{chart.TimeSeries{
+			Style:   chart.Style{StrokeColor: drawingColor(colorAxis), StrokeWidth: 1},
+			XValues: []time.Time{start, end},
+			YValues: []float64{yMin, yMin},
+		}}
+
+		c.Elements = append(c.Elements, func(r chart.Renderer, cb chart.Box, defaults chart.Style) {
+			chart.Draw.TextWithin(r, "No data", cb, chart.Style{
+				Font:                defaults.Font,
+				FontSize:            chart.DefaultFontSize,
+				FontColor:           drawingColor(colorAxis),
+				TextHorizontalAlign: chart.TextHorizontalAlignCenter,
+				TextVerticalAlign:   chart.TextVerticalAlignMiddle,
+			})
+		})
+	}
+
+	if len(g.Series) > 0 {
+		c.Elements = append(c.Elements, chart.Legend(legend, chart.Style{FontColor: drawingColor(colorText)}))
+	}
+
+	return c
+}
+
+// values returns the series values to be drawn (cumulated if stacked), along with their minimal and maximal values.
+func (g Graph) values() ([][]float64, float64, float64) {
+	min, max := math.NaN(), math.NaN()
+
+	result := make([][]float64, len(g.Series))
+	for i, s := range g.Series {
+		result[i] = make([]float64, len(s.Points))
+
+		for j, p := range s.Points {
+			v := float64(p.Value)
+
+			if g.Type == TypeStacked {
+				if math.IsNaN(v) {
+					v = 0
+				}
+
+				if i > 0 && j < len(result[i-1]) {
+					v += result[i-1][j]
+				}
+			}
+
+			result[i][j] = v
+
+			if !math.IsNaN(v) {
+				if v < min || math.IsNaN(min) {
+					min = v
+				}
+				if v > max || math.IsNaN(max) {
+					max = v
+				}
+			}
+		}
+	}
+
+	// Include zero baseline for area graphs
+	if (g.Type == TypeArea || g.Type == TypeStacked) && !math.IsNaN(min) {
+		min, max = math.Min(min, 0), math.Max(max, 0)
+	}
+
+	return result, min, max
+}
+
+// segments returns the indexes of the series points grouped in contiguous non-null segments.
+func segments(points []series.Point, values []float64) [][]int {
+	result := [][]int{}
+	current := []int{}
+
+	for i := range points {
+		if math.IsNaN(values[i]) {
+			if len(current) > 0 {
+				result = append(result, current)
+				current = []int{}
+			}
+			continue
+		}
+
+		current = append(current, i)
+	}
+
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+
+	return result
+}
+
+func seriesColor(s string, idx int) color.RGBA {
+	if c, ok := parseColor(s); ok {
+		return c
+	}
+
+	c, _ := parseColor(palette[idx%len(palette)])
+	return c
+}
+
+// parseColor parses an hexadecimal color definition (either #rgb or #rrggbb).
+func parseColor(s string) (color.RGBA, bool) {
+	hex := func(c byte) (uint8, bool) {
+		switch {
+		case c >= '0' && c <= '9':
+			return c - '0', true
+		case c >= 'a' && c <= 'f':
+			return c - 'a' + 10, true
+		case c >= 'A' && c <= 'F':
+			return c - 'A' + 10, true
+		}
+		return 0, false
+	}
+
+	if len(s) != 4 && len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, false
+	}
+
+	digits := make([]uint8, len(s)-1)
+	for i := range digits {
+		v, ok := hex(s[i+1])
+		if !ok {
+			return color.RGBA{}, false
+		}
+		digits[i] = v
+	}
+
+	if len(digits) == 3 {
+		return color.RGBA{digits[0] * 17, digits[1] * 17, digits[2] * 17, 0xff}, true
+	}
+
+	return color.RGBA{digits[0]<<4 | digits[1], digits[2]<<4 | digits[3], digits[4]<<4 | digits[5], 0xff}, true
+}
+
+func drawingColor(c color.RGBA) drawing.Color {
+	return drawing.Color{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// opaqueFillColor returns the color of an area fill blended over a white background, preventing stacked areas
+// overlapping parts from mixing up.
+func opaqueFillColor(c color.RGBA) drawing.Color {
+	blend := func(v uint8) uint8 {
+		return uint8((uint(v)*areaFillAlpha + 0xff*(0xff-areaFillAlpha)) / 0xff)
+	}
+
+	return drawing.Color{R: blend(c.R), G: blend(c.G), B: blend(c.B), A: 0xff}
+}
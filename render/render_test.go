@@ -0,0 +1,165 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"facette.io/facette/series"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SVG(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, SVG(buf, testGraph(TypeArea)))
+
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output, `<svg `))
+	assert.Contains(t, output, `width="400" height="200"`)
+	assert.Contains(t, output, `>Load &amp; more</text>`)
+	assert.Contains(t, output, `>host2</text>`)
+	assert.Contains(t, output, `>hôte&lt;3&gt;</text>`)
+	assert.Contains(t, output, `fill:rgba(255,0,0,0.3)`)
+	assert.NotContains(t, output, `No data`)
+}
+
+func Test_SVG_NoData(t *testing.T) {
+	g := testGraph(TypeLine)
+	g.Series = nil
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, SVG(buf, g))
+	assert.Contains(t, buf.String(), `>No data</text>`)
+}
+
+func Test_PNG(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, PNG(buf, testGraph(TypeStacked)))
+
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatalf("failed to decode image: %s", err)
+	}
+
+	assert.Equal(t, 400, img.Bounds().Dx())
+	assert.Equal(t, 200, img.Bounds().Dy())
+
+	// Check for series color presence
+	found := false
+	for y := 0; y < 200 && !found; y++ {
+		for x := 0; x < 400; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r>>8 > 0xe0 && g>>8 < 0x20 && b>>8 < 0x20 {
+				found = true
+				break
+			}
+		}
+	}
+	assert.True(t, found)
+}
+
+func Test_niceTicks(t *testing.T) {
+	ticks, min, max := niceTicks(0.12, 9.7, 5)
+	assert.Equal(t, []float64{0, 2, 4, 6, 8, 10}, ticks)
+	assert.Equal(t, 0.0, min)
+	assert.Equal(t, 10.0, max)
+
+	ticks, _, _ = niceTicks(0.1, 0.3, 3)
+	assert.Equal(t, []float64{0.1, 0.2, 0.3}, ticks)
+
+	ticks, _, _ = niceTicks(math.NaN(), math.NaN(), 5)
+	assert.Equal(t, 0.0, ticks[0])
+	assert.Equal(t, 1.0, ticks[len(ticks)-1])
+
+	// Large values having a narrow spread must not loop forever
+	for _, bounds := range [][2]float64{{1e17, 1e17 + 16}, {1e17, 1e17}, {-1e300, -1e300}, {1e15, 1e15 + 1}} {
+		ticks, min, max := niceTicks(bounds[0], bounds[1], 5)
+		assert.True(t, len(ticks) >= 2 && len(ticks) <= 11, bounds)
+		assert.True(t, min <= bounds[0] && max >= bounds[1], bounds)
+	}
+}
+
+func Test_timeTicks(t *testing.T) {
+	start := time.Date(2017, 7, 14, 2, 37, 0, 0, time.UTC)
+
+	ticks, layout := timeTicks(start, start.Add(time.Hour), 4)
+	assert.Equal(t, "15:04", layout)
+	assert.Equal(t, []time.Time{
+		time.Date(2017, 7, 14, 2, 45, 0, 0, time.UTC),
+		time.Date(2017, 7, 14, 3, 0, 0, 0, time.UTC),
+		time.Date(2017, 7, 14, 3, 15, 0, 0, time.UTC),
+		time.Date(2017, 7, 14, 3, 30, 0, 0, time.UTC),
+	}, ticks)
+
+	_, layout = timeTicks(start, start.Add(30*24*time.Hour), 6)
+	assert.Equal(t, "Jan 02", layout)
+}
+
+func Test_formatValue(t *testing.T) {
+	for _, test := range []struct {
+		value    float64
+		unit     string
+		base     string
+		expected string
+	}{
+		{0, UnitMetric, "", "0"},
+		{1234.5678, UnitFixed, "", "1234.57"},
+		{1500, UnitMetric, "", "1.5k"},
+		{0.002, UnitMetric, "", "2m"},
+		{3 * 1024 * 1024, UnitBinary, "", "3Mi"},
+		{1500, UnitDuration, "ms", "1.5s"},
+		{250, UnitDuration, "us", "250µs"},
+	} {
+		assert.Equal(t, test.expected, formatValue(test.value, test.unit, test.base))
+	}
+}
+
+func Test_parseColor(t *testing.T) {
+	c, ok := parseColor("#ff602a")
+	assert.True(t, ok)
+	assert.Equal(t, color.RGBA{0xff, 0x60, 0x2a, 0xff}, c)
+
+	c, ok = parseColor("#fa0")
+	assert.True(t, ok)
+	assert.Equal(t, color.RGBA{0xff, 0xaa, 0x00, 0xff}, c)
+
+	for _, s := range []string{"", "red", "#ff60", "#gggggg"} {
+		_, ok = parseColor(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func testGraph(typ string) Graph {
+	startTime := time.Unix(1500000000, 0)
+
+	g := Graph{
+		Title:     "Load & more",
+		Type:      typ,
+		Unit:      UnitMetric,
+		Width:     400,
+		Height:    200,
+		StartTime: startTime,
+		EndTime:   startTime.Add(10 * time.Minute),
+	}
+
+	for i, name := range []string{"host1", "host2", "hôte<3>"} {
+		s := Series{Name: name}
+		if i == 0 {
+			s.Color = "#ff0000"
+		}
+
+		for j, v := range []series.Value{1, 2, series.Value(math.NaN()), 4, 3, 5, 2, 1, 2, 3} {
+			s.Points = append(s.Points, series.Point{
+				Time:  startTime.Add(time.Duration(j) * time.Minute),
+				Value: v * series.Value(i+1),
+			})
+		}
+
+		g.Series = append(g.Series, s)
+	}
+
+	return g
+}
@@ -0,0 +1,210 @@
+package series
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Export formats
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Export time formats
+const (
+	TimeFormatRFC3339 = "rfc3339"
+	TimeFormatEpoch   = "epoch"
+)
+
+// ExportOptions represents a points response export options set.
+type ExportOptions struct {
+	TimeFormat string
+	Summary    bool
+}
+
+// WriteCSV writes the response series as CSV records, having a time column followed by one column per series. If
+// requested, summary values are written after an empty record, their first column being set to the summary name.
+func (r Response) WriteCSV(w io.Writer, opts ExportOptions) error {
+	times, values := r.columns()
+
+	cw := csv.NewWriter(w)
+
+	record := make([]string, len(r.Series)+1)
+
+	record[0] = "time"
+	for i, s := range r.Series {
+		record[i+1] = s.Name
+	}
+
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for i, t := range times {
+		record[0] = formatTime(t, opts.TimeFormat)
+		for j := range r.Series {
+			record[j+1] = formatValue(values[j][i])
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	if opts.Summary {
+		if err := cw.Write([]string{""}); err != nil {
+			return err
+		}
+
+		for _, key := range r.summaryKeys() {
+			record[0] = key
+			for j, s := range r.Series {
+				if v, ok := s.Summary[key]; ok {
+					record[j+1] = formatValue(v)
+				} else {
+					record[j+1] = ""
+				}
+			}
+
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// WriteNDJSON writes the response series as newline-delimited JSON objects. A first object has a "series" field
+// listing the series names, followed by one object per timestamp having a "time" field and a "values" field listing
+// the series values in the same order (series names not being necessarily unique). If requested, a final object is
+// written having a "summary" field listing the series summary values in the same order.
+func (r Response) WriteNDJSON(w io.Writer, opts ExportOptions) error {
+	times, values := r.columns()
+
+	enc := json.NewEncoder(w)
+
+	names := make([]string, len(r.Series))
+	for i, s := range r.Series {
+		names[i] = s.Name
+	}
+
+	if err := enc.Encode(map[string]interface{}{"series": names}); err != nil {
+		return err
+	}
+
+	for i, t := range times {
+		entry := struct {
+			Time   interface{} `json:"time"`
+			Values []Value     `json:"values"`
+		}{
+			Values: make([]Value, len(r.Series)),
+		}
+
+		if opts.TimeFormat == TimeFormatEpoch {
+			entry.Time = t.Unix()
+		} else {
+			entry.Time = t.UTC().Format(time.RFC3339)
+		}
+
+		for j := range r.Series {
+			entry.Values[j] = values[j][i]
+		}
+
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	if opts.Summary {
+		summary := make([]map[string]Value, len(r.Series))
+		for i, s := range r.Series {
+			summary[i] = s.Summary
+		}
+
+		return enc.Encode(map[string]interface{}{"summary": summary})
+	}
+
+	return nil
+}
+
+// columns returns the sorted list of unique timestamps found in the response series, along with series values
+// aligned on those timestamps (null values being set for missing points).
+func (r Response) columns() ([]time.Time, [][]Value) {
+	seen := make(map[int64]time.Time)
+	for _, s := range r.Series {
+		for _, p := range s.Points {
+			seen[p.Time.UnixNano()] = p.Time
+		}
+	}
+
+	keys := make([]int64, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	times := make([]time.Time, len(keys))
+	index := make(map[int64]int, len(keys))
+	for i, k := range keys {
+		times[i] = seen[k]
+		index[k] = i
+	}
+
+	values := make([][]Value, len(r.Series))
+	for i, s := range r.Series {
+		values[i] = make([]Value, len(times))
+		for j := range values[i] {
+			values[i][j] = Value(math.NaN())
+		}
+
+		for _, p := range s.Points {
+			values[i][index[p.Time.UnixNano()]] = p.Value
+		}
+	}
+
+	return times, values
+}
+
+// summaryKeys returns the sorted list of summary names found in the response series.
+func (r Response) summaryKeys() []string {
+	seen := make(map[string]struct{})
+	for _, s := range r.Series {
+		for k := range s.Summary {
+			seen[k] = struct{}{}
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatTime(t time.Time, format string) string {
+	if format == TimeFormatEpoch {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatValue(v Value) string {
+	if v.IsNaN() {
+		return ""
+	}
+
+	return strconv.FormatFloat(float64(v), 'f', -1, 64)
+}
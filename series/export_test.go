@@ -0,0 +1,80 @@
+package series
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testExportResponse = Response{
+	Series: []ResponseSeries{
+		{
+			Series: Series{
+				Points: []Point{
+					{Time: time.Unix(1500000000, 0), Value: 1.5},
+					{Time: time.Unix(1500000060, 0), Value: nan},
+				},
+				Summary: map[string]Value{"avg": 1.5, "last": 1.5},
+			},
+			Name: "host1.load",
+		},
+		{
+			Series: Series{
+				Points: []Point{
+					{Time: time.Unix(1500000060, 0), Value: 2},
+					{Time: time.Unix(1500000120, 0), Value: 3},
+				},
+				Summary: map[string]Value{"avg": 2.5},
+			},
+			Name: "host2, load",
+		},
+	},
+}
+
+func Test_Response_WriteCSV(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, testExportResponse.WriteCSV(buf, ExportOptions{}))
+	assert.Equal(t, `time,host1.load,"host2, load"
+2017-07-14T02:40:00Z,1.5,
+2017-07-14T02:41:00Z,,2
+2017-07-14T02:42:00Z,,3
+`, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, testExportResponse.WriteCSV(buf, ExportOptions{TimeFormat: TimeFormatEpoch, Summary: true}))
+	assert.Equal(t, `time,host1.load,"host2, load"
+1500000000,1.5,
+1500000060,,2
+1500000120,,3
+
+avg,1.5,2.5
+last,1.5,
+`, buf.String())
+}
+
+func Test_Response_WriteNDJSON(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, testExportResponse.WriteNDJSON(buf, ExportOptions{TimeFormat: TimeFormatEpoch, Summary: true}))
+	assert.Equal(t, `{"series":["host1.load","host2, load"]}
+{"time":1500000000,"values":[1.5,null]}
+{"time":1500000060,"values":[null,2]}
+{"time":1500000120,"values":[null,3]}
+{"summary":[{"avg":1.5,"last":1.5},{"avg":2.5}]}
+`, buf.String())
+
+	buf.Reset()
+	assert.Nil(t, testExportResponse.WriteNDJSON(buf, ExportOptions{}))
+	assert.Contains(t, buf.String(), `{"time":"2017-07-14T02:40:00Z","values":`)
+
+	// Series having the same name must not overwrite each other
+	r := Response{Series: []ResponseSeries{testExportResponse.Series[0], testExportResponse.Series[0]}}
+
+	buf.Reset()
+	assert.Nil(t, r.WriteNDJSON(buf, ExportOptions{TimeFormat: TimeFormatEpoch}))
+	assert.Equal(t, `{"series":["host1.load","host1.load"]}
+{"time":1500000000,"values":[1.5,1.5]}
+{"time":1500000060,"values":[null,null]}
+`, buf.String())
+}
@@ -0,0 +1,467 @@
+package series
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var exprFuncs = map[string]struct {
+	minArgs int
+	maxArgs int
+	fn      func([]float64) float64
+}{
+	"abs":  {1, 1, func(v []float64) float64 { return math.Abs(v[0]) }},
+	"sqrt": {1, 1, func(v []float64) float64 { return math.Sqrt(v[0]) }},
+	"log": {1, 1, func(v []float64) float64 {
+		if v[0] <= 0 {
+			return math.NaN()
+		}
+		return math.Log10(v[0])
+	}},
+	"min": {1, -1, func(v []float64) float64 {
+		result := v[0]
+		for _, x := range v[1:] {
+			result = math.Min(result, x)
+		}
+		return result
+	}},
+	"max": {1, -1, func(v []float64) float64 {
+		result := v[0]
+		for _, x := range v[1:] {
+			result = math.Max(result, x)
+		}
+		return result
+	}},
+	"sum": {1, -1, func(v []float64) float64 {
+		result := 0.0
+		for _, x := range v {
+			result += x
+		}
+		return result
+	}},
+	"avg": {1, -1, func(v []float64) float64 {
+		result := 0.0
+		for _, x := range v {
+			result += x
+		}
+		return result / float64(len(v))
+	}},
+}
+
+// Expr represents a series arithmetic expression instance.
+//
+// Expressions support numeric constants, series references (either identifiers such as "requests" or double-quoted
+// names such as "http 5xx"), the +, -, * and / operators, parentheses and the abs, sqrt, log (base 10), min, max, sum
+// and avg functions.
+type Expr struct {
+	root exprNode
+	refs []string
+}
+
+// ParseExpr parses a series arithmetic expression.
+func ParseExpr(s string) (*Expr, error) {
+	tokens, err := exprLex(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	} else if t := p.peek(); t.kind != exprTokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+
+	e := &Expr{root: root}
+	e.refs = exprRefs(root, nil)
+	if len(e.refs) == 0 {
+		return nil, fmt.Errorf("expression must reference at least one series")
+	}
+
+	return e, nil
+}
+
+// Refs returns the list of series names referenced by the expression.
+func (e *Expr) Refs() []string {
+	return e.refs
+}
+
+// Eval evaluates the expression given a scope of named series, all series being expected to be normalized.
+func (e *Expr) Eval(scope map[string]Series) (Series, error) {
+	var times []time.Time
+
+	// Check for referenced series availability and normalization
+	for _, name := range e.refs {
+		s, ok := scope[name]
+		if !ok {
+			return Series{}, fmt.Errorf("unknown series %q", name)
+		}
+
+		if times == nil {
+			times = make([]time.Time, len(s.Points))
+			for i, p := range s.Points {
+				times[i] = p.Time
+			}
+		} else if len(s.Points) != len(times) {
+			return Series{}, ErrUnnormalizedSeries
+		}
+	}
+
+	values := e.root.eval(scope, len(times))
+
+	result := Series{Points: make([]Point, len(times))}
+	for i := range times {
+		// Replace infinite values (e.g. resulting from divisions by zero) with null values, as they can't be marshaled
+		if math.IsInf(values[i], 0) {
+			values[i] = math.NaN()
+		}
+
+		result.Points[i] = Point{Time: times[i], Value: Value(values[i])}
+	}
+
+	return result, nil
+}
+
+type exprNode interface {
+	eval(scope map[string]Series, n int) []float64
+}
+
+type exprNumber float64
+
+func (e exprNumber) eval(scope map[string]Series, n int) []float64 {
+	result := make([]float64, n)
+	for i := range result {
+		result[i] = float64(e)
+	}
+	return result
+}
+
+type exprRef string
+
+func (e exprRef) eval(scope map[string]Series, n int) []float64 {
+	result := make([]float64, n)
+	for i, p := range scope[string(e)].Points {
+		result[i] = float64(p.Value)
+	}
+	return result
+}
+
+type exprUnary struct {
+	operand exprNode
+}
+
+func (e exprUnary) eval(scope map[string]Series, n int) []float64 {
+	result := e.operand.eval(scope, n)
+	for i := range result {
+		result[i] = -result[i]
+	}
+	return result
+}
+
+type exprBinary struct {
+	op          byte
+	left, right exprNode
+}
+
+func (e exprBinary) eval(scope map[string]Series, n int) []float64 {
+	left, right := e.left.eval(scope, n), e.right.eval(scope, n)
+
+	for i := range left {
+		switch e.op {
+		case '+':
+			left[i] += right[i]
+		case '-':
+			left[i] -= right[i]
+		case '*':
+			left[i] *= right[i]
+		case '/':
+			if right[i] == 0 {
+				left[i] = math.NaN()
+			} else {
+				left[i] /= right[i]
+			}
+		}
+	}
+
+	return left
+}
+
+type exprCall struct {
+	name string
+	args []exprNode
+}
+
+func (e exprCall) eval(scope map[string]Series, n int) []float64 {
+	args := make([][]float64, len(e.args))
+	for i, arg := range e.args {
+		args[i] = arg.eval(scope, n)
+	}
+
+	result := make([]float64, n)
+	values := make([]float64, len(args))
+
+	for i := range result {
+		for j := range args {
+			values[j] = args[j][i]
+		}
+
+		// Propagate null values
+		result[i] = exprFuncs[e.name].fn(values)
+		for _, v := range values {
+			if math.IsNaN(v) {
+				result[i] = math.NaN()
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func exprRefs(node exprNode, refs []string) []string {
+	switch e := node.(type) {
+	case exprRef:
+		for _, ref := range refs {
+			if ref == string(e) {
+				return refs
+			}
+		}
+		refs = append(refs, string(e))
+
+	case exprUnary:
+		refs = exprRefs(e.operand, refs)
+
+	case exprBinary:
+		refs = exprRefs(e.left, refs)
+		refs = exprRefs(e.right, refs)
+
+	case exprCall:
+		for _, arg := range e.args {
+			refs = exprRefs(arg, refs)
+		}
+	}
+
+	return refs
+}
+
+const (
+	exprTokenEOF = iota
+	exprTokenNumber
+	exprTokenIdent
+	exprTokenString
+	exprTokenOperator
+)
+
+type exprToken struct {
+	kind  int
+	value string
+	pos   int
+}
+
+func exprLex(s string) ([]exprToken, error) {
+	tokens := []exprToken{}
+
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case strings.ContainsRune("+-*/(),", c):
+			tokens = append(tokens, exprToken{kind: exprTokenOperator, value: string(c), pos: i})
+			i++
+
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.' || s[j] == 'e' || s[j] == 'E' ||
+				(s[j] == '-' || s[j] == '+') && (s[j-1] == 'e' || s[j-1] == 'E')) {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenNumber, value: s[i:j], pos: i})
+			i = j
+
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '.' || unicode.IsLetter(rune(s[j])) ||
+				unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenIdent, value: s[i:j], pos: i})
+			i = j
+
+		case c == '"':
+			j := strings.IndexByte(s[i+1:], '"')
+			if j == -1 {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenString, value: s[i+1 : i+1+j], pos: i})
+			i += j + 2
+
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", c, i)
+		}
+	}
+
+	return append(tokens, exprToken{kind: exprTokenEOF, pos: len(s)}), nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.pos]
+	if t.kind != exprTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == exprTokenOperator && t.value == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseExpr parses additive expressions (i.e. "term (('+' | '-') term)*").
+func (p *exprParser) parseExpr() (exprNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var op byte
+
+		if p.accept("+") {
+			op = '+'
+		} else if p.accept("-") {
+			op = '-'
+		} else {
+			return left, nil
+		}
+
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+// parseTerm parses multiplicative expressions (i.e. "factor (('*' | '/') factor)*").
+func (p *exprParser) parseTerm() (exprNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var op byte
+
+		if p.accept("*") {
+			op = '*'
+		} else if p.accept("/") {
+			op = '/'
+		} else {
+			return left, nil
+		}
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+// parseFactor parses unary expressions, constants, series references, function calls and parenthesized expressions.
+func (p *exprParser) parseFactor() (exprNode, error) {
+	if p.accept("-") {
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		return exprUnary{operand: operand}, nil
+	} else if p.accept("(") {
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		} else if !p.accept(")") {
+			t := p.peek()
+			return nil, fmt.Errorf("expected \")\" at position %d", t.pos)
+		}
+
+		return node, nil
+	}
+
+	t := p.next()
+
+	switch t.kind {
+	case exprTokenNumber:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+
+		return exprNumber(v), nil
+
+	case exprTokenString:
+		return exprRef(t.value), nil
+
+	case exprTokenIdent:
+		if !p.accept("(") {
+			return exprRef(t.value), nil
+		}
+
+		f, ok := exprFuncs[t.value]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %q at position %d", t.value, t.pos)
+		}
+
+		call := exprCall{name: t.value}
+		if !p.accept(")") {
+			for {
+				arg, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+
+				if p.accept(")") {
+					break
+				} else if !p.accept(",") {
+					return nil, fmt.Errorf("expected \",\" or \")\" at position %d", p.peek().pos)
+				}
+			}
+		}
+
+		if len(call.args) < f.minArgs || f.maxArgs != -1 && len(call.args) > f.maxArgs {
+			return nil, fmt.Errorf("invalid number of arguments for function %q", t.value)
+		}
+
+		return call, nil
+
+	case exprTokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
@@ -0,0 +1,102 @@
+package series
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseExpr(t *testing.T) {
+	for s, refs := range map[string][]string{
+		"errors / requests * 100":             {"errors", "requests"},
+		`-("http 5xx" + errors) / 2`:          {"http 5xx", "errors"},
+		"max(cpu.user, cpu.system, 1e-3) - 1": {"cpu.user", "cpu.system"},
+		"abs(a - a)":                          {"a"},
+	} {
+		e, err := ParseExpr(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, refs, e.Refs(), s)
+	}
+
+	for s, msg := range map[string]string{
+		"":              "unexpected end of expression",
+		"1 + 2":         "expression must reference at least one series",
+		"a +":           "unexpected end of expression",
+		"(a + b":        "expected \")\" at position 6",
+		"a b":           "unexpected \"b\" at position 2",
+		"unknown(a)":    "unknown function \"unknown\" at position 0",
+		"abs(a, b)":     "invalid number of arguments for function \"abs\"",
+		`a + "b`:        "unterminated string at position 4",
+		"a % b":         "unexpected '%' at position 2",
+		"max(a b)":      "expected \",\" or \")\" at position 6",
+		"a * (b + 1))":  "unexpected \")\" at position 11",
+		"a + 1.2.3 * b": "invalid number \"1.2.3\" at position 4",
+	} {
+		_, err := ParseExpr(s)
+		if assert.NotNil(t, err, s) {
+			assert.Equal(t, msg, err.Error(), s)
+		}
+	}
+}
+
+func Test_Expr_Eval(t *testing.T) {
+	startTime := time.Unix(1500000000, 0)
+
+	newSeries := func(values ...Value) Series {
+		s := Series{}
+		for i, v := range values {
+			s.Points = append(s.Points, Point{Time: startTime.Add(time.Duration(i) * time.Minute), Value: v})
+		}
+		return s
+	}
+
+	scope := map[string]Series{
+		"errors":   newSeries(1, 0, 5, nan),
+		"requests": newSeries(10, 0, 20, 30),
+		"other":    newSeries(1, 2),
+	}
+
+	e, err := ParseExpr("errors / requests * 100")
+	assert.Nil(t, err)
+
+	result, err := e.Eval(scope)
+	assert.Nil(t, err)
+	assert.Len(t, result.Points, 4)
+	assert.Equal(t, startTime.Add(3*time.Minute), result.Points[3].Time)
+	assert.Equal(t, Value(10), result.Points[0].Value)
+	assert.True(t, result.Points[1].Value.IsNaN())
+	assert.Equal(t, Value(25), result.Points[2].Value)
+	assert.True(t, result.Points[3].Value.IsNaN())
+
+	e, err = ParseExpr("-max(errors, 2) + 2 * (1 + 2)")
+	assert.Nil(t, err)
+
+	result, err = e.Eval(scope)
+	assert.Nil(t, err)
+	assert.Equal(t, []Value{4, 4, 1}, []Value{result.Points[0].Value, result.Points[1].Value, result.Points[2].Value})
+	assert.True(t, result.Points[3].Value.IsNaN())
+
+	// Check for infinite and undefined results being replaced with null values
+	for _, input := range []string{"log(requests)", "errors / 0", "-errors / (requests - requests)"} {
+		e, err = ParseExpr(input)
+		assert.Nil(t, err)
+
+		result, err = e.Eval(scope)
+		assert.Nil(t, err)
+		assert.True(t, result.Points[1].Value.IsNaN(), input)
+
+		_, err = json.Marshal(result.Points)
+		assert.Nil(t, err, input)
+	}
+
+	// Check for unknown or unnormalized series
+	e, _ = ParseExpr("errors + unknown")
+	_, err = e.Eval(scope)
+	assert.Equal(t, `unknown series "unknown"`, err.Error())
+
+	e, _ = ParseExpr("errors + other")
+	_, err = e.Eval(scope)
+	assert.Equal(t, ErrUnnormalizedSeries, err)
+}
@@ -0,0 +1,97 @@
+package series
+
+import "math"
+
+// HoltWinters computes an additive Holt-Winters (triple exponential smoothing) forecast of a normalized series, along
+// with its upper and lower confidence bands located at a given number of smoothed deviations from the forecast.
+//
+// The season value is expressed in number of points: if lower than 2, no seasonal component is applied (i.e. Holt's
+// linear trend method). Null values are replaced by their forecast value when updating the smoothing state.
+func HoltWinters(s Series, season int, alpha, beta, gamma, deviations float64) (Series, Series, Series) {
+	var intercept, slope float64
+
+	count := len(s.Points)
+
+	forecast := Series{Points: make([]Point, count)}
+	upper := Series{Points: make([]Point, count)}
+	lower := Series{Points: make([]Point, count)}
+
+	if season < 2 {
+		season = 1
+	}
+
+	seasonals := make([]float64, season)
+	deviation := 0.0
+	started := false
+
+	for i, p := range s.Points {
+		forecast.Points[i].Time = p.Time
+		upper.Points[i].Time = p.Time
+		lower.Points[i].Time = p.Time
+
+		actual := float64(p.Value)
+		seasonal := 0.0
+		if season > 1 {
+			seasonal = seasonals[i%season]
+		}
+
+		// Initialize smoothing state on first non-null value
+		if !started {
+			if math.IsNaN(actual) {
+				forecast.Points[i].Value = Value(math.NaN())
+				upper.Points[i].Value = Value(math.NaN())
+				lower.Points[i].Value = Value(math.NaN())
+				continue
+			}
+
+			intercept = actual
+			started = true
+		}
+
+		prediction := intercept + slope + seasonal
+		if math.IsNaN(actual) {
+			actual = prediction
+		}
+
+		forecast.Points[i].Value = Value(prediction)
+		upper.Points[i].Value = Value(prediction + deviations*deviation)
+		lower.Points[i].Value = Value(prediction - deviations*deviation)
+
+		// Update smoothing state
+		lastIntercept := intercept
+
+		intercept = alpha*(actual-seasonal) + (1-alpha)*(intercept+slope)
+		slope = beta*(intercept-lastIntercept) + (1-beta)*slope
+		if season > 1 {
+			seasonals[i%season] = gamma*(actual-intercept) + (1-gamma)*seasonal
+		}
+		deviation = gamma*math.Abs(actual-prediction) + (1-gamma)*deviation
+	}
+
+	return forecast, upper, lower
+}
+
+// Anomalies returns a new series having the values of the points whose z-score (i.e. the number of standard deviations
+// from the series mean) exceeds a given threshold, other points values being null.
+func Anomalies(s Series, threshold float64) Series {
+	result := Series{Points: make([]Point, len(s.Points))}
+
+	values := s.validValues()
+
+	mean := sumValues(values) / float64(len(values))
+
+	stddev := 0.0
+	for _, v := range values {
+		stddev += math.Pow(v-mean, 2)
+	}
+	stddev = math.Sqrt(stddev / float64(len(values)))
+
+	for i, p := range s.Points {
+		result.Points[i] = Point{Time: p.Time, Value: Value(math.NaN())}
+		if !p.Value.IsNaN() && stddev > 0 && math.Abs(float64(p.Value)-mean)/stddev > threshold {
+			result.Points[i].Value = p.Value
+		}
+	}
+
+	return result
+}
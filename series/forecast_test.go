@@ -0,0 +1,68 @@
+package series
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HoltWinters(t *testing.T) {
+	startTime := time.Unix(1500000000, 0)
+
+	// Check for constant series
+	s := Series{}
+	for i := 0; i < 10; i++ {
+		s.Points = append(s.Points, Point{Time: startTime.Add(time.Duration(i) * time.Minute), Value: 5})
+	}
+	s.Points[0].Value = nan
+	s.Points[4].Value = nan
+
+	forecast, upper, lower := HoltWinters(s, 2, 0.5, 0.1, 0.5, 3)
+	assert.True(t, forecast.Points[0].Value.IsNaN())
+	assert.True(t, upper.Points[0].Value.IsNaN())
+	assert.True(t, lower.Points[0].Value.IsNaN())
+
+	for i := 1; i < 10; i++ {
+		assert.Equal(t, s.Points[i].Time, forecast.Points[i].Time)
+		assert.Equal(t, Value(5), forecast.Points[i].Value)
+		assert.Equal(t, Value(5), upper.Points[i].Value)
+		assert.Equal(t, Value(5), lower.Points[i].Value)
+	}
+
+	// Check for seasonal series convergence
+	s = Series{}
+	for i := 0; i < 100; i++ {
+		s.Points = append(s.Points, Point{
+			Time:  startTime.Add(time.Duration(i) * time.Minute),
+			Value: Value(10 + 5*math.Sin(float64(i)*math.Pi/2)),
+		})
+	}
+
+	forecast, upper, lower = HoltWinters(s, 4, 0.5, 0.1, 0.5, 3)
+	for i := 80; i < 100; i++ {
+		assert.InDelta(t, float64(s.Points[i].Value), float64(forecast.Points[i].Value), 0.1, "point #%d", i)
+		assert.True(t, lower.Points[i].Value <= forecast.Points[i].Value)
+		assert.True(t, upper.Points[i].Value >= forecast.Points[i].Value)
+	}
+}
+
+func Test_Anomalies(t *testing.T) {
+	s := Series{}
+	for i, v := range []Value{1, 1, 1, nan, 1, 1, 1, 1, 1, 1, 10} {
+		s.Points = append(s.Points, Point{Time: time.Unix(int64(i*60), 0), Value: v})
+	}
+
+	result := Anomalies(s, 2)
+	assert.Len(t, result.Points, 11)
+
+	for i, p := range result.Points {
+		assert.Equal(t, s.Points[i].Time, p.Time)
+		if i == 10 {
+			assert.Equal(t, Value(10), p.Value)
+		} else {
+			assert.True(t, p.Value.IsNaN(), "point #%d", i)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package series
 
 import (
+	"fmt"
 	"math"
+	"sort"
 	"time"
 )
 
@@ -19,6 +21,12 @@ const (
 	ConsolidateMin
 	// ConsolidateSum represents a sum consolidation type.
 	ConsolidateSum
+	// ConsolidateMedian represents a median value consolidation type.
+	ConsolidateMedian
+	// ConsolidatePercentile represents a percentile value consolidation type.
+	ConsolidatePercentile
+	// ConsolidateCount represents a non-null values count consolidation type.
+	ConsolidateCount
 )
 
 const (
@@ -28,6 +36,33 @@ const (
 	OperatorAverage
 	// OperatorSum represents a sum operation type.
 	OperatorSum
+	// OperatorExpression represents an arithmetic expression operation type.
+	OperatorExpression
+	// OperatorMin represents a minimal value operation type.
+	OperatorMin
+	// OperatorMax represents a maximal value operation type.
+	OperatorMax
+	// OperatorMedian represents a median value operation type.
+	OperatorMedian
+	// OperatorPercentile represents a percentile value operation type.
+	OperatorPercentile
+	// OperatorCount represents a non-null values count operation type.
+	OperatorCount
+	// OperatorStddev represents a standard deviation operation type.
+	OperatorStddev
+)
+
+const (
+	// FillNull represents a null values keeping fill strategy.
+	FillNull = "null"
+	// FillZero represents a zero value fill strategy.
+	FillZero = "zero"
+	// FillPrevious represents a previous non-null value fill strategy.
+	FillPrevious = "previous"
+	// FillLinear represents a linear interpolation fill strategy.
+	FillLinear = "linear"
+	// FillDrop represents a fill strategy dropping time steps having null values in all series.
+	FillDrop = "drop"
 )
 
 type bucket struct {
@@ -35,8 +70,9 @@ type bucket struct {
 	time   time.Time
 }
 
-// Consolidate consolidates points buckets based on consolidation function.
-func (b bucket) Consolidate(consolidation int) Point {
+// Consolidate consolidates points buckets based on consolidation function, the percentile value being only used by
+// the percentile consolidation type.
+func (b bucket) Consolidate(consolidation int, pct float64) Point {
 	point := Point{
 		Time:  b.time,
 		Value: Value(math.NaN()),
@@ -44,6 +80,10 @@ func (b bucket) Consolidate(consolidation int) Point {
 
 	length := len(b.points)
 	if length == 0 {
+		if consolidation == ConsolidateCount {
+			point.Value = 0
+		}
+
 		return point
 	}
 
@@ -99,13 +139,35 @@ func (b bucket) Consolidate(consolidation int) Point {
 				point.Value = p.Value
 			}
 		}
+
+	case ConsolidateMedian, ConsolidatePercentile, ConsolidateCount:
+		values := []float64{}
+		for _, p := range b.points {
+			if !p.Value.IsNaN() {
+				values = append(values, float64(p.Value))
+			}
+		}
+
+		if consolidation == ConsolidateCount {
+			point.Value = Value(len(values))
+		} else if len(values) > 0 && consolidation == ConsolidateMedian {
+			point.Value = Value(median(values))
+		} else if len(values) > 0 {
+			sort.Float64s(values)
+			point.Value = Value(percentile(values, pct))
+		}
 	}
 
 	return point
 }
 
-// Normalize aligns multiple point series on a common time step, consolidates points samples if necessary.
-func Normalize(series []Series, startTime, endTime time.Time, sample int, consolidation int) ([]Series, error) {
+// Normalize aligns multiple point series on a common time step, consolidates points samples if necessary (the
+// percentile value being only used by the percentile consolidation type) and fills null values given a fill strategy
+// (see Fill* constants, an empty value being equivalent to FillNull).
+//
+// Resulting series all have exactly sample points, unless the FillDrop fill strategy is used.
+func Normalize(series []Series, startTime, endTime time.Time, sample int, consolidation int, pct float64,
+	fill string) ([]Series, error) {
 	if sample <= 0 {
 		return nil, ErrInvalidSample
 	}
@@ -115,54 +177,38 @@ func Normalize(series []Series, startTime, endTime time.Time, sample int, consol
 		return nil, ErrEmptySeries
 	}
 
-	result := make([]Series, length)
-	buckets := make([][]bucket, length)
-
 	// Calculate the common step for all series based on time range and requested sampling
 	step := endTime.Sub(startTime) / time.Duration(sample)
+	if step <= 0 {
+		return nil, ErrInvalidSample
+	}
+
+	result := make([]Series, length)
 
 	// Dispatch points into proper time step buckets and then apply consolidation function
 	for i, s := range series {
-		if s.Points == nil {
-			// Generate NaN points for whole series if none found (needed for gap detection)
-			result[i] = Series{
-				Points:  make([]Point, sample),
-				Summary: make(map[string]Value),
-			}
-
-			for j := 0; j < sample; j++ {
-				result[i].Points[j] = Point{
-					Time:  startTime.Add(time.Duration(j) * step).Round(time.Second),
-					Value: Value(math.NaN()),
-				}
-			}
-
-			continue
-		}
-
-		buckets[i] = make([]bucket, sample)
+		buckets := make([]bucket, sample)
 
 		// Initialize time steps
 		for j := 0; j < sample; j++ {
-			buckets[i][j] = bucket{
+			buckets[j] = bucket{
 				points: make([]Point, 0),
-				time:   startTime.Add(time.Duration(j) * (step + 1)).Round(time.Second),
+				time:   startTime.Add(time.Duration(j) * step).Round(time.Second),
 			}
 		}
 
 		for _, p := range s.Points {
 			// Discard series points out of time specs range
-			if p.Time.Before(startTime) || p.Time.After(endTime) {
+			if p.Time.Before(startTime) || !p.Time.Before(endTime) {
 				continue
 			}
 
-			// Stop if index goes beyond the requested sample
-			idx := int64(float64(p.Time.UnixNano()-startTime.UnixNano())/float64(step.Nanoseconds())+1) - 1
-			if idx >= int64(sample) {
-				continue
+			idx := int(p.Time.Sub(startTime) / step)
+			if idx >= sample {
+				idx = sample - 1
 			}
 
-			buckets[i][idx].points = append(buckets[i][idx].points, p)
+			buckets[idx].points = append(buckets[idx].points, p)
 		}
 
 		result[i] = Series{
@@ -170,26 +216,180 @@ func Normalize(series []Series, startTime, endTime time.Time, sample int, consol
 			Summary: make(map[string]Value),
 		}
 
-		// Consolidate point buckets
-		for j := range buckets[i] {
-			result[i].Points[j] = buckets[i][j].Consolidate(consolidation)
+		// Consolidate point buckets (series having no points at all being only filled with null values, as needed
+		// for gap detection)
+		for j := range buckets {
+			if s.Points == nil {
+				result[i].Points[j] = Point{Time: buckets[j].time, Value: Value(math.NaN())}
+			} else {
+				result[i].Points[j] = buckets[j].Consolidate(consolidation, pct)
+			}
 		}
 	}
 
+	switch fill {
+	case "", FillNull:
+		// noop
+
+	case FillZero:
+		for i := range result {
+			result[i].ZeroNulls()
+		}
+
+	case FillPrevious:
+		for i := range result {
+			result[i].fillPrevious()
+		}
+
+	case FillLinear:
+		for i := range result {
+			result[i].fillLinear()
+		}
+
+	case FillDrop:
+		dropNulls(result)
+
+	default:
+		return nil, fmt.Errorf("unsupported %q fill strategy", fill)
+	}
+
 	return result, nil
 }
 
+func (s *Series) fillPrevious() {
+	prev := Value(math.NaN())
+
+	for i := range s.Points {
+		if s.Points[i].Value.IsNaN() {
+			s.Points[i].Value = prev
+		} else {
+			prev = s.Points[i].Value
+		}
+	}
+}
+
+func (s *Series) fillLinear() {
+	prev := -1
+
+	for i := range s.Points {
+		if s.Points[i].Value.IsNaN() {
+			continue
+		}
+
+		// Interpolate null values located between two non-null ones
+		if prev != -1 && i-prev > 1 {
+			start, end := s.Points[prev], s.Points[i]
+			duration := float64(end.Time.Sub(start.Time))
+
+			for j := prev + 1; j < i; j++ {
+				ratio := float64(s.Points[j].Time.Sub(start.Time)) / duration
+				s.Points[j].Value = start.Value + Value(ratio)*(end.Value-start.Value)
+			}
+		}
+
+		prev = i
+	}
+}
+
+// dropNulls removes the time steps having null values in all normalized series.
+func dropNulls(series []Series) {
+	count := len(series[0].Points)
+
+	for idx := count - 1; idx >= 0; idx-- {
+		drop := true
+		for _, s := range series {
+			if !s.Points[idx].Value.IsNaN() {
+				drop = false
+				break
+			}
+		}
+
+		if drop {
+			for i := range series {
+				series[i].Points = append(series[i].Points[:idx], series[i].Points[idx+1:]...)
+			}
+		}
+	}
+}
+
 // Average returns a new series averaging each datapoints.
 func Average(series []Series) (Series, error) {
-	return applyOperator(series, OperatorAverage)
+	return applyOperator(series, func(values []float64) float64 {
+		return sumValues(values) / float64(len(values))
+	})
 }
 
 // Sum returns a new series summing each datapoints.
 func Sum(series []Series) (Series, error) {
-	return applyOperator(series, OperatorSum)
+	return applyOperator(series, sumValues)
+}
+
+// Min returns a new series with the minimal value of each datapoints.
+func Min(series []Series) (Series, error) {
+	return applyOperator(series, func(values []float64) float64 {
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Min(result, v)
+		}
+		return result
+	})
+}
+
+// Max returns a new series with the maximal value of each datapoints.
+func Max(series []Series) (Series, error) {
+	return applyOperator(series, func(values []float64) float64 {
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Max(result, v)
+		}
+		return result
+	})
+}
+
+// Median returns a new series with the median value of each datapoints.
+func Median(series []Series) (Series, error) {
+	return applyOperator(series, median)
+}
+
+// Percentile returns a new series with the nth percentile value of each datapoints.
+func Percentile(series []Series, pct float64) (Series, error) {
+	return applyOperator(series, func(values []float64) float64 {
+		sort.Float64s(values)
+		return percentile(values, pct)
+	})
+}
+
+// Count returns a new series counting non-null values of each datapoints.
+func Count(series []Series) (Series, error) {
+	result, err := applyOperator(series, func(values []float64) float64 {
+		return float64(len(values))
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Report no values as a zero count
+	result.ZeroNulls()
+
+	return result, nil
 }
 
-func applyOperator(series []Series, operator int) (Series, error) {
+// Stddev returns a new series with the standard deviation of each datapoints.
+func Stddev(series []Series) (Series, error) {
+	return applyOperator(series, func(values []float64) float64 {
+		avg := sumValues(values) / float64(len(values))
+
+		sum := 0.0
+		for _, v := range values {
+			sum += (v - avg) * (v - avg)
+		}
+
+		return math.Sqrt(sum / float64(len(values)))
+	})
+}
+
+// applyOperator applies an operation function on each datapoints non-null values, null being set if none available.
+func applyOperator(series []Series, fn func([]float64) float64) (Series, error) {
 	length := len(series)
 	if length == 0 {
 		return Series{}, ErrEmptySeries
@@ -202,8 +402,10 @@ func applyOperator(series []Series, operator int) (Series, error) {
 		Summary: make(map[string]Value),
 	}
 
+	values := make([]float64, 0, length)
+
 	for i := 0; i < count; i++ {
-		sumCount := 0
+		values = values[:0]
 
 		result.Points[i].Time = series[0].Points[i].Time
 
@@ -216,16 +418,23 @@ func applyOperator(series []Series, operator int) (Series, error) {
 				continue
 			}
 
-			result.Points[i].Value += s.Points[i].Value
-			sumCount++
+			values = append(values, float64(s.Points[i].Value))
 		}
 
-		if sumCount == 0 {
+		if len(values) == 0 {
 			result.Points[i].Value = Value(math.NaN())
-		} else if operator == OperatorAverage {
-			result.Points[i].Value /= Value(sumCount)
+		} else {
+			result.Points[i].Value = Value(fn(values))
 		}
 	}
 
 	return result, nil
 }
+
+func sumValues(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
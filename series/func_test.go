@@ -66,27 +66,42 @@ func init() {
 }
 
 func Test_Consolidate_Average(t *testing.T) {
-	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 11.75}, testBucket.Consolidate(ConsolidateAverage))
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 11.75}, testBucket.Consolidate(ConsolidateAverage, 0))
 }
 
 func Test_Consolidate_Sum(t *testing.T) {
-	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 47}, testBucket.Consolidate(ConsolidateSum))
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 47}, testBucket.Consolidate(ConsolidateSum, 0))
 }
 
 func Test_Consolidate_First(t *testing.T) {
-	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 17}, testBucket.Consolidate(ConsolidateFirst))
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 17}, testBucket.Consolidate(ConsolidateFirst, 0))
 }
 
 func Test_Consolidate_Last(t *testing.T) {
-	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 2}, testBucket.Consolidate(ConsolidateLast))
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 2}, testBucket.Consolidate(ConsolidateLast, 0))
 }
 
 func Test_Consolidate_Min(t *testing.T) {
-	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 2}, testBucket.Consolidate(ConsolidateMin))
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 2}, testBucket.Consolidate(ConsolidateMin, 0))
 }
 
 func Test_Consolidate_Max(t *testing.T) {
-	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 25}, testBucket.Consolidate(ConsolidateMax))
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 25}, testBucket.Consolidate(ConsolidateMax, 0))
+}
+
+func Test_Consolidate_Median(t *testing.T) {
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 10}, testBucket.Consolidate(ConsolidateMedian, 0))
+}
+
+func Test_Consolidate_Percentile(t *testing.T) {
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 23}, testBucket.Consolidate(ConsolidatePercentile, 75))
+}
+
+func Test_Consolidate_Count(t *testing.T) {
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 4}, testBucket.Consolidate(ConsolidateCount, 0))
+
+	emptyBucket := bucket{time: time.Unix(0, 0)}
+	assert.Equal(t, Point{Time: time.Unix(0, 0), Value: 0}, emptyBucket.Consolidate(ConsolidateCount, 0))
 }
 
 func Test_Normalize_Average(t *testing.T) {
@@ -371,6 +386,63 @@ func Test_Normalize_Sum(t *testing.T) {
 	}, ConsolidateSum, t)
 }
 
+func Test_Normalize_Sample(t *testing.T) {
+	startTime := time.Unix(0, 0)
+
+	input := []Series{{Points: []Point{{Time: time.Unix(0, 0), Value: 1}, {Time: time.Unix(99, 0), Value: 2}}}, {}}
+
+	series, err := Normalize(input, startTime, startTime.Add(100*time.Second), 7, ConsolidateAverage, 0, FillNull)
+	assert.Nil(t, err)
+
+	for _, s := range series {
+		assert.Len(t, s.Points, 7)
+		assert.Equal(t, time.Unix(86, 0), s.Points[6].Time)
+	}
+
+	assert.Equal(t, Value(1), series[0].Points[0].Value)
+	assert.Equal(t, Value(2), series[0].Points[6].Value)
+}
+
+func Test_Normalize_Fill(t *testing.T) {
+	startTime := time.Unix(0, 0)
+
+	input := []Series{
+		{Points: []Point{
+			{Time: time.Unix(10, 0), Value: 2}, {Time: time.Unix(30, 0), Value: Value(math.NaN())},
+			{Time: time.Unix(50, 0), Value: 8}, {Time: time.Unix(70, 0), Value: 4},
+		}},
+		{Points: []Point{{Time: time.Unix(30, 0), Value: 1}}},
+	}
+
+	for fill, expected := range map[string][][]Value{
+		FillNull:     {{2, nan, 8, 4, nan, nan}, {nan, 1, nan, nan, nan, nan}},
+		FillZero:     {{2, 0, 8, 4, 0, 0}, {0, 1, 0, 0, 0, 0}},
+		FillPrevious: {{2, 2, 8, 4, 4, 4}, {nan, 1, 1, 1, 1, 1}},
+		FillLinear:   {{2, 5, 8, 4, nan, nan}, {nan, 1, nan, nan, nan, nan}},
+		FillDrop:     {{2, nan, 8, 4}, {nan, 1, nan, nan}},
+	} {
+		series, err := Normalize(input, startTime, startTime.Add(120*time.Second), 6, ConsolidateAverage, 0, fill)
+		assert.Nil(t, err)
+
+		for i, s := range series {
+			if !assert.Len(t, s.Points, len(expected[i]), fill) {
+				continue
+			}
+
+			for j, p := range s.Points {
+				if expected[i][j].IsNaN() {
+					assert.True(t, p.Value.IsNaN(), "%s: series #%d: point #%d", fill, i, j)
+				} else {
+					assert.Equal(t, expected[i][j], p.Value, "%s: series #%d: point #%d", fill, i, j)
+				}
+			}
+		}
+	}
+
+	_, err := Normalize(input, startTime, startTime.Add(120*time.Second), 6, ConsolidateAverage, 0, "unknown")
+	assert.NotNil(t, err)
+}
+
 func Test_Average(t *testing.T) {
 	expected := Series{
 		Points: []Point{
@@ -397,10 +469,58 @@ func Test_Sum(t *testing.T) {
 	}
 }
 
+func Test_Min(t *testing.T) {
+	testOperator(Min, []Value{61, 62, 71, 56, 43}, t)
+}
+
+func Test_Max(t *testing.T) {
+	testOperator(Max, []Value{89, 70, 98, 93, 72}, t)
+}
+
+func Test_Median(t *testing.T) {
+	testOperator(Median, []Value{75, 69, 84.5, 78, 66}, t)
+}
+
+func Test_Percentile(t *testing.T) {
+	testOperator(func(series []Series) (Series, error) {
+		return Percentile(series, 50)
+	}, []Value{75, 69, 84.5, 78, 66}, t)
+
+	testOperator(func(series []Series) (Series, error) {
+		return Percentile(series, 95)
+	}, []Value{89, 70, 98, 93, 72}, t)
+}
+
+func Test_Count(t *testing.T) {
+	testOperator(Count, []Value{2, 3, 2, 3, 3}, t)
+
+	series, err := Count([]Series{{Points: []Point{{Value: Value(math.NaN())}}}})
+	assert.Nil(t, err)
+	assert.Equal(t, Value(0), series.Points[0].Value)
+}
+
+func Test_Stddev(t *testing.T) {
+	testOperator(Stddev, []Value{14, 3.559026084010437, 13.5, 15.195028426721974, 12.498888839501783}, t)
+}
+
+func testOperator(fn func([]Series) (Series, error), values []Value, t *testing.T) {
+	expected := Series{}
+	for _, v := range values {
+		expected.Points = append(expected.Points, Point{Value: v})
+	}
+
+	series, err := fn(testSeries)
+	assert.Nil(t, err)
+	if !compareSeries(expected, series) {
+		assert.Fail(t, fmt.Sprintf("Not equal: \nexpected: %#v\nactual  : %#v", expected, series))
+	}
+}
+
 func testNormalize(expected []Series, consolidation int, t *testing.T) {
 	startTime := time.Unix(0, 0)
 
-	series, err := Normalize(testSeriesNormalize, startTime, startTime.Add(300*time.Second), 10, consolidation)
+	series, err := Normalize(testSeriesNormalize, startTime, startTime.Add(300*time.Second), 10, consolidation, 0,
+		FillNull)
 	assert.Nil(t, err)
 	assert.Len(t, series, len(expected))
 
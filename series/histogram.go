@@ -0,0 +1,126 @@
+package series
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultHistogramBuckets represents the default histogram value buckets count.
+const DefaultHistogramBuckets = 10
+
+// HistogramRequest represents a histogram request instance.
+type HistogramRequest struct {
+	Request
+	Buckets int       `json:"buckets,omitempty"`
+	Bounds  []float64 `json:"bounds,omitempty"`
+}
+
+// HistogramResponse represents a histogram response instance.
+type HistogramResponse struct {
+	Start   string                 `json:"start"`
+	End     string                 `json:"end"`
+	Bounds  []float64              `json:"bounds"`
+	Steps   []HistogramStep        `json:"steps"`
+	Options map[string]interface{} `json:"options"`
+	Partial bool                   `json:"partial"`
+}
+
+// HistogramStep represents a histogram time step instance, holding the number of values found in each value bucket.
+type HistogramStep struct {
+	Time   time.Time
+	Counts []int
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (step HistogramStep) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]interface{}{int(step.Time.Unix()), step.Counts})
+}
+
+// HistogramBounds returns linear value buckets bounds covering all the values of a list of series.
+func HistogramBounds(series []Series, buckets int) []float64 {
+	min, max := math.NaN(), math.NaN()
+
+	for _, s := range series {
+		for _, v := range s.validValues() {
+			if v < min || math.IsNaN(min) {
+				min = v
+			}
+			if v > max || math.IsNaN(max) {
+				max = v
+			}
+		}
+	}
+
+	if math.IsNaN(min) || buckets <= 0 {
+		return []float64{}
+	} else if min == max {
+		max = min + 1
+	}
+
+	result := make([]float64, buckets+1)
+	for i := range result {
+		result[i] = min + float64(i)*(max-min)/float64(buckets)
+	}
+	result[buckets] = max
+
+	return result
+}
+
+// Histogram returns the distribution of normalized series values for each time step, given a list of ascending
+// value buckets bounds. Buckets include their lower bound, the last one also including its upper bound. Values out
+// of bounds are discarded.
+func Histogram(series []Series, bounds []float64) ([]HistogramStep, error) {
+	var count int
+
+	length := len(series)
+	if length == 0 {
+		return nil, ErrEmptySeries
+	}
+
+	for _, s := range series {
+		if s.Points == nil {
+			continue
+		} else if count == 0 {
+			count = len(s.Points)
+		} else if len(s.Points) != count {
+			return nil, ErrUnnormalizedSeries
+		}
+	}
+
+	buckets := len(bounds) - 1
+	if buckets < 0 {
+		buckets = 0
+	}
+
+	result := make([]HistogramStep, count)
+
+	for i := range result {
+		result[i].Counts = make([]int, buckets)
+
+		for _, s := range series {
+			if s.Points == nil {
+				continue
+			}
+
+			if result[i].Time.IsZero() {
+				result[i].Time = s.Points[i].Time
+			}
+
+			v := float64(s.Points[i].Value)
+			if math.IsNaN(v) || buckets == 0 || v < bounds[0] || v > bounds[buckets] {
+				continue
+			}
+
+			idx := sort.Search(len(bounds), func(j int) bool { return bounds[j] > v }) - 1
+			if idx == buckets {
+				idx--
+			}
+
+			result[i].Counts[idx]++
+		}
+	}
+
+	return result, nil
+}
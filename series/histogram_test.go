@@ -0,0 +1,54 @@
+package series
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HistogramBounds(t *testing.T) {
+	assert.Equal(t, []float64{0, 2.5, 5, 7.5, 10}, HistogramBounds([]Series{
+		{Points: []Point{{Value: 2}, {Value: 10}, {Value: nan}}},
+		{Points: []Point{{Value: 0}, {Value: 5}}},
+	}, 4))
+
+	assert.Equal(t, []float64{3, 3.5, 4}, HistogramBounds([]Series{{Points: []Point{{Value: 3}}}}, 2))
+	assert.Equal(t, []float64{}, HistogramBounds([]Series{{Points: []Point{{Value: nan}}}}, 2))
+}
+
+func Test_Histogram(t *testing.T) {
+	startTime := time.Unix(1500000000, 0)
+
+	newSeries := func(values ...Value) Series {
+		s := Series{}
+		for i, v := range values {
+			s.Points = append(s.Points, Point{Time: startTime.Add(time.Duration(i) * time.Minute), Value: v})
+		}
+		return s
+	}
+
+	result, err := Histogram([]Series{
+		newSeries(1, 5, nan),
+		{},
+		newSeries(2, 10, 11),
+		newSeries(9, 0, -1),
+	}, []float64{0, 5, 10})
+	assert.Nil(t, err)
+	assert.Equal(t, []HistogramStep{
+		{Time: startTime, Counts: []int{2, 1}},
+		{Time: startTime.Add(time.Minute), Counts: []int{1, 2}},
+		{Time: startTime.Add(2 * time.Minute), Counts: []int{0, 0}},
+	}, result)
+
+	data, err := json.Marshal(result[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "[1500000000,[2,1]]", string(data))
+
+	_, err = Histogram([]Series{newSeries(1, 2), newSeries(1)}, []float64{0, 1})
+	assert.Equal(t, ErrUnnormalizedSeries, err)
+
+	_, err = Histogram(nil, []float64{0, 1})
+	assert.Equal(t, ErrEmptySeries, err)
+}
@@ -18,4 +18,7 @@ type Request struct {
 	Graph      *storage.Graph `json:"graph"`
 	Attributes maputil.Map    `json:"attributes,omitempty"`
 	Normalize  bool           `json:"normalize"`
+	Format     string         `json:"format,omitempty"`
+	TimeFormat string         `json:"time_format,omitempty"`
+	Summary    bool           `json:"summary,omitempty"`
 }
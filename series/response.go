@@ -1,11 +1,22 @@
 package series
 
+import "strings"
+
+// Series status codes
+const (
+	StatusOK            = "ok"
+	StatusNotInCatalog  = "not_in_catalog"
+	StatusProviderError = "provider_error"
+	StatusInvalid       = "invalid"
+)
+
 // Response represents a point response instance.
 type Response struct {
 	Start   string                 `json:"start"`
 	End     string                 `json:"end"`
 	Series  []ResponseSeries       `json:"series"`
 	Options map[string]interface{} `json:"options"`
+	Partial bool                   `json:"partial"`
 }
 
 // ResponseSeries represents a point response series instance.
@@ -13,4 +24,37 @@ type ResponseSeries struct {
 	Series
 	Name    string                 `json:"name"`
 	Options map[string]interface{} `json:"options"`
+	Status  Status                 `json:"status"`
+}
+
+// Status represents a point response series status instance.
+type Status struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// MergeStatus merges multiple series statuses into a single one, the resulting status being set to the first
+// non-OK status code found and its message to the list of unique messages.
+func MergeStatus(statuses []Status) Status {
+	result := Status{Code: StatusOK}
+
+	messages := []string{}
+	seen := make(map[string]struct{})
+
+	for _, status := range statuses {
+		if status.Code == StatusOK {
+			continue
+		} else if result.Code == StatusOK {
+			result.Code = status.Code
+		}
+
+		if _, ok := seen[status.Message]; status.Message != "" && !ok {
+			messages = append(messages, status.Message)
+			seen[status.Message] = struct{}{}
+		}
+	}
+
+	result.Message = strings.Join(messages, "; ")
+
+	return result
 }
@@ -0,0 +1,99 @@
+package series
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MergeStatus(t *testing.T) {
+	assert.Equal(t, Status{Code: StatusOK}, MergeStatus([]Status{{Code: StatusOK}, {Code: StatusOK}}))
+
+	assert.Equal(t, Status{Code: StatusProviderError, Message: "a: timed out; b: failure"}, MergeStatus([]Status{
+		{Code: StatusOK},
+		{Code: StatusProviderError, Message: "a: timed out"},
+		{Code: StatusNotInCatalog},
+		{Code: StatusProviderError, Message: "a: timed out"},
+		{Code: StatusProviderError, Message: "b: failure"},
+	}))
+}
+
+func Test_Response_Delta(t *testing.T) {
+	newSeries := func(name string, times ...int64) ResponseSeries {
+		s := ResponseSeries{Name: name}
+		for _, ts := range times {
+			s.Points = append(s.Points, Point{Time: time.Unix(ts, 0), Value: Value(ts)})
+		}
+		return s
+	}
+
+	prev := Response{
+		Series: []ResponseSeries{
+			newSeries("a", 0, 10, 20),
+			newSeries("b"),
+			newSeries("a", 0, 10),
+		},
+	}
+
+	cur := Response{
+		Start: "1970-01-01T00:00:10Z",
+		Series: []ResponseSeries{
+			newSeries("a", 10, 20, 30, 40),
+			newSeries("b", 30),
+			newSeries("a", 10, 20, 30),
+		},
+		Partial: true,
+	}
+
+	expected := Response{
+		Start: "1970-01-01T00:00:10Z",
+		Series: []ResponseSeries{
+			newSeries("a", 20, 30, 40),
+			newSeries("b", 30),
+			newSeries("a", 10, 20, 30),
+		},
+		Partial: true,
+	}
+
+	delta, ok := cur.Delta(prev)
+	assert.True(t, ok)
+	assert.Equal(t, expected, delta)
+	assert.Equal(t, 4, len(cur.Series[0].Points))
+
+	delta, ok = cur.Delta(cur)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(delta.Series[0].Points))
+
+	// Check for mismatching series
+	_, ok = cur.Delta(Response{Series: prev.Series[:2]})
+	assert.False(t, ok)
+
+	_, ok = cur.Delta(Response{Series: []ResponseSeries{newSeries("a"), newSeries("c"), newSeries("a")}})
+	assert.False(t, ok)
+}
+
+func Test_StreamWindow(t *testing.T) {
+	ref := time.Date(2017, 6, 7, 12, 29, 8, 0, time.UTC)
+
+	// 1h span with 400 points sample gives 9s steps
+	startTime, endTime := StreamWindow(ref, time.Hour, 400)
+	assert.Equal(t, ref.Truncate(9*time.Second), endTime)
+	assert.Equal(t, endTime.Add(-400*9*time.Second), startTime)
+
+	for _, offset := range []time.Duration{5 * time.Second, 30 * time.Second, 17 * time.Minute} {
+		nextStart, nextEnd := StreamWindow(ref.Add(offset), time.Hour, 400)
+		assert.False(t, nextEnd.After(ref.Add(offset)))
+		assert.Equal(t, time.Duration(0), nextStart.Sub(startTime)%(9*time.Second))
+		assert.Equal(t, 400*9*time.Second, nextEnd.Sub(nextStart))
+	}
+
+	// Steps are at least 1s long
+	startTime, endTime = StreamWindow(ref, time.Minute, 400)
+	assert.Equal(t, ref, endTime)
+	assert.Equal(t, ref.Add(-400*time.Second), startTime)
+
+	// Steps are rounded up to the second
+	startTime, endTime = StreamWindow(ref, 1000*time.Second, 400)
+	assert.Equal(t, 400*3*time.Second, endTime.Sub(startTime))
+}
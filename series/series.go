@@ -4,6 +4,24 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SummaryCount represents a non-null values count summary statistic.
+	SummaryCount = "count"
+	// SummaryDelta represents a last minus first value summary statistic.
+	SummaryDelta = "delta"
+	// SummaryFirst represents a first value summary statistic.
+	SummaryFirst = "first"
+	// SummaryMedian represents a median value summary statistic.
+	SummaryMedian = "median"
+	// SummaryStddev represents a standard deviation summary statistic.
+	SummaryStddev = "stddev"
+	// SummarySum represents a sum summary statistic.
+	SummarySum = "sum"
 )
 
 // Series represents a time series instance.
@@ -21,15 +39,24 @@ func (s *Series) Scale(factor Value) {
 	}
 }
 
-// Summarize calculates the min/max/average/last and percentile values for a time series.
-func (s *Series) Summarize(percentiles []float64) {
+// Shift moves all series points by a given time offset.
+func (s *Series) Shift(offset time.Duration) {
+	for i := range s.Points {
+		s.Points[i].Time = s.Points[i].Time.Add(offset)
+	}
+}
+
+// Summarize calculates the min/max/average/last and percentile values for a time series, along with optional
+// extended statistics (see Summary* constants).
+func (s *Series) Summarize(percentiles []float64, stats []string) {
 	var (
-		min, max, total, current Value
-		nValidPoints             int64
+		min, max, total, first, current Value
+		nValidPoints                    int64
 	)
 
 	min = Value(math.NaN())
 	max = Value(math.NaN())
+	first = Value(math.NaN())
 	current = Value(math.NaN())
 
 	for i := range s.Points {
@@ -41,6 +68,9 @@ func (s *Series) Summarize(percentiles []float64) {
 			if current > max || max.IsNaN() {
 				max = current
 			}
+			if first.IsNaN() {
+				first = current
+			}
 
 			total += current
 			nValidPoints++
@@ -51,11 +81,51 @@ func (s *Series) Summarize(percentiles []float64) {
 		s.Summary = make(map[string]Value)
 	}
 
+	avg := Value(math.NaN())
+	if nValidPoints > 0 {
+		avg = total / Value(nValidPoints)
+	}
+
 	s.Summary["min"] = min
 	s.Summary["max"] = max
-	s.Summary["avg"] = total / Value(nValidPoints)
+	s.Summary["avg"] = avg
 	s.Summary["last"] = current
 
+	for _, stat := range stats {
+		switch stat {
+		case SummaryCount:
+			s.Summary[stat] = Value(nValidPoints)
+
+		case SummaryDelta:
+			s.Summary[stat] = current - first
+
+		case SummaryFirst:
+			s.Summary[stat] = first
+
+		case SummaryMedian:
+			s.Summary[stat] = Value(math.NaN())
+			if values := s.validValues(); len(values) > 0 {
+				s.Summary[stat] = Value(median(values))
+			}
+
+		case SummaryStddev:
+			s.Summary[stat] = Value(math.NaN())
+			if nValidPoints > 0 {
+				sum := 0.0
+				for _, v := range s.validValues() {
+					sum += math.Pow(v-float64(avg), 2)
+				}
+				s.Summary[stat] = Value(math.Sqrt(sum / float64(nValidPoints)))
+			}
+
+		case SummarySum:
+			s.Summary[stat] = Value(math.NaN())
+			if nValidPoints > 0 {
+				s.Summary[stat] = total
+			}
+		}
+	}
+
 	if len(percentiles) > 0 {
 		s.Percentiles(percentiles)
 	}
@@ -63,18 +133,12 @@ func (s *Series) Summarize(percentiles []float64) {
 
 // Percentiles calculates the percentile values for a time series.
 func (s *Series) Percentiles(values []float64) {
-	var set []float64
-
 	// Stop if no percentile value provided
 	if len(values) == 0 {
 		return
 	}
 
-	for i := range s.Points {
-		if !s.Points[i].Value.IsNaN() {
-			set = append(set, float64(s.Points[i].Value))
-		}
-	}
+	set := s.validValues()
 
 	count := len(set)
 	if count == 0 {
@@ -85,22 +149,88 @@ func (s *Series) Percentiles(values []float64) {
 
 	// Calculate percentiles
 	for _, pct := range values {
-		label := fmt.Sprintf("%gth", pct)
-
-		rank := (pct / 100) * float64(count+1)
-		rankInt := int(rank)
-		rankFrac := rank - float64(rankInt)
-
-		if rank <= 0.0 {
-			s.Summary[label] = Value(set[0])
-			continue
-		} else if rank-1.0 >= float64(count) {
-			s.Summary[label] = Value(set[count-1])
-			continue
+		s.Summary[fmt.Sprintf("%gth", pct)] = Value(percentile(set, pct))
+	}
+}
+
+// Rank returns the series indexes ordered by a summary value (either "avg", "last", "max", "min" or a percentile such
+// as "95th"), in descending order unless ascending is set. Series having no valid summary value are ranked last.
+func Rank(series []Series, by string, ascending bool) ([]int, error) {
+	var percentiles []float64
+
+	switch by {
+	case "avg", "last", "max", "min":
+
+	default:
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(by, "th"), 64)
+		if err != nil || !strings.HasSuffix(by, "th") || pct <= 0 || pct > 100 {
+			return nil, fmt.Errorf("invalid %q rank value", by)
+		}
+
+		percentiles = []float64{pct}
+		by = fmt.Sprintf("%gth", pct)
+	}
+
+	values := make([]Value, len(series))
+	for i := range series {
+		s := Series{Points: series[i].Points}
+		s.Summarize(percentiles, nil)
+
+		if v, ok := s.Summary[by]; ok {
+			values[i] = v
+		} else {
+			values[i] = Value(math.NaN())
 		}
+	}
 
-		s.Summary[label] = Value(set[rankInt-1] + rankFrac*(set[rankInt]-set[rankInt-1]))
+	result := make([]int, len(series))
+	for i := range result {
+		result[i] = i
 	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := values[result[i]], values[result[j]]
+		if a.IsNaN() {
+			return false
+		} else if b.IsNaN() {
+			return true
+		} else if ascending {
+			return a < b
+		}
+		return a > b
+	})
+
+	return result, nil
+}
+
+// percentile returns the percentile value of a sorted set of values.
+func percentile(set []float64, pct float64) float64 {
+	count := len(set)
+
+	rank := (pct / 100) * float64(count+1)
+	rankInt := int(rank)
+	rankFrac := rank - float64(rankInt)
+
+	if rank <= 0.0 || rankInt == 0 {
+		return set[0]
+	} else if rank-1.0 >= float64(count) || rankInt >= count {
+		return set[count-1]
+	}
+
+	return set[rankInt-1] + rankFrac*(set[rankInt]-set[rankInt-1])
+}
+
+// validValues returns the list of non-null point values.
+func (s *Series) validValues() []float64 {
+	var values []float64
+
+	for i := range s.Points {
+		if !s.Points[i].Value.IsNaN() {
+			values = append(values, float64(s.Points[i].Value))
+		}
+	}
+
+	return values
 }
 
 // ZeroNulls replaces all null point values by zero.
@@ -24,6 +24,51 @@ func Test_Scale(t *testing.T) {
 	}
 }
 
+func Test_Shift(t *testing.T) {
+	startTime := time.Unix(1500000000, 0)
+
+	series := Series{
+		Points: []Point{{Time: startTime, Value: 1}, {Time: startTime.Add(time.Minute), Value: 2}},
+	}
+
+	series.Shift(7 * 24 * time.Hour)
+
+	assert.Equal(t, []Point{
+		{Time: startTime.Add(7 * 24 * time.Hour), Value: 1},
+		{Time: startTime.Add(7*24*time.Hour + time.Minute), Value: 2},
+	}, series.Points)
+}
+
+func Test_Rank(t *testing.T) {
+	series := []Series{
+		{Points: []Point{{Value: 1}, {Value: 9}, {Value: 2}}},
+		{Points: []Point{{Value: Value(math.NaN())}}},
+		{Points: []Point{{Value: 4}, {Value: 5}, {Value: 6}}},
+		{Points: []Point{{Value: 3}, {Value: 3}, {Value: 3}}},
+	}
+
+	for _, test := range []struct {
+		by        string
+		ascending bool
+		expected  []int
+	}{
+		{"avg", false, []int{2, 0, 3, 1}},
+		{"avg", true, []int{3, 0, 2, 1}},
+		{"max", false, []int{0, 2, 3, 1}},
+		{"last", false, []int{2, 3, 0, 1}},
+		{"50th", false, []int{2, 3, 0, 1}},
+	} {
+		result, err := Rank(series, test.by, test.ascending)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, result, test.by)
+	}
+
+	for _, by := range []string{"unknown", "95", "0th", "101th"} {
+		_, err := Rank(series, by, false)
+		assert.NotNil(t, err, by)
+	}
+}
+
 func Test_Summarize(t *testing.T) {
 	series := Series{
 		Points: []Point{
@@ -74,14 +119,48 @@ func Test_Summarize(t *testing.T) {
 		{&seriesNeg, "90th", -55.199999999999996},
 	}
 
-	series.Summarize([]float64{20, 50, 90})
-	seriesNeg.Summarize([]float64{20, 50, 90})
+	series.Summarize([]float64{20, 50, 90}, nil)
+	seriesNeg.Summarize([]float64{20, 50, 90}, nil)
 
 	for _, c := range checks {
 		assert.Equal(t, c.value, c.series.Summary[c.label])
 	}
 }
 
+func Test_Summarize_Stats(t *testing.T) {
+	series := Series{
+		Points: []Point{
+			{Value: Value(math.NaN())}, {Value: 2}, {Value: 4}, {Value: 4}, {Value: Value(math.NaN())},
+			{Value: 4}, {Value: 5}, {Value: 5}, {Value: 7}, {Value: 9},
+		},
+	}
+
+	series.Summarize(nil, []string{SummaryCount, SummaryDelta, SummaryFirst, SummaryMedian, SummaryStddev,
+		SummarySum})
+
+	assert.Equal(t, map[string]Value{
+		"min":    2,
+		"max":    9,
+		"avg":    5,
+		"last":   9,
+		"count":  8,
+		"delta":  7,
+		"first":  2,
+		"median": 4.5,
+		"stddev": 2,
+		"sum":    40,
+	}, series.Summary)
+
+	// Check for series having no valid points
+	empty := Series{Points: []Point{{Value: Value(math.NaN())}}}
+	empty.Summarize(nil, []string{SummaryCount, SummaryStddev, SummarySum})
+
+	assert.True(t, empty.Summary["avg"].IsNaN())
+	assert.True(t, empty.Summary["stddev"].IsNaN())
+	assert.True(t, empty.Summary["sum"].IsNaN())
+	assert.Equal(t, Value(0), empty.Summary["count"])
+}
+
 func compareSeries(expected, actual Series) bool {
 	if len(actual.Points) != len(expected.Points) {
 		return false
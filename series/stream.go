@@ -0,0 +1,66 @@
+package series
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultStreamInterval represents the default stream refresh interval.
+	DefaultStreamInterval = 10 * time.Second
+	// MinStreamInterval represents the minimal stream refresh interval.
+	MinStreamInterval = time.Second
+)
+
+// StreamRequest represents a points stream request instance.
+type StreamRequest struct {
+	Request
+	Interval string `json:"interval,omitempty"`
+}
+
+// StreamWindow returns the bounds of a time span ending at a given time, aligned on the sampling step (rounded up to
+// the second) of a span and sample pair. Normalized points timestamps thus remain identical when sliding the window.
+func StreamWindow(t time.Time, span time.Duration, sample int) (time.Time, time.Time) {
+	if sample <= 0 {
+		sample = DefaultSample
+	}
+
+	step := span / time.Duration(sample)
+	if step < time.Second {
+		step = time.Second
+	} else if step%time.Second != 0 {
+		step = step.Truncate(time.Second) + time.Second
+	}
+
+	endTime := t.Truncate(step)
+
+	return endTime.Add(-step * time.Duration(sample)), endTime
+}
+
+// Delta returns a copy of the response only keeping, for each series, the points starting from the last point of
+// the same series in a previous response (this point being kept as its value might have been updated since). Series
+// are matched by position, and false is returned if the response series don't match the previous ones.
+func (r Response) Delta(prev Response) (Response, bool) {
+	if len(r.Series) != len(prev.Series) {
+		return r, false
+	}
+
+	result := r
+	result.Series = make([]ResponseSeries, len(r.Series))
+
+	for i, s := range r.Series {
+		if s.Name != prev.Series[i].Name {
+			return r, false
+		}
+
+		result.Series[i] = s
+
+		if n := len(prev.Series[i].Points); n > 0 {
+			last := prev.Series[i].Points[n-1].Time
+			idx := sort.Search(len(s.Points), func(j int) bool { return !s.Points[j].Time.Before(last) })
+			result.Series[i].Points = s.Points[idx:]
+		}
+	}
+
+	return result, true
+}
@@ -0,0 +1,242 @@
+package series
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TransformAbs represents an absolute value transform type.
+	TransformAbs = "abs"
+	// TransformDerivative represents a derivative transform type.
+	TransformDerivative = "derivative"
+	// TransformIntegral represents an integral transform type.
+	TransformIntegral = "integral"
+	// TransformLog represents a logarithmic scale transform type.
+	TransformLog = "log"
+	// TransformMovingAverage represents a moving average transform type.
+	TransformMovingAverage = "moving_average"
+	// TransformMovingMedian represents a moving median transform type.
+	TransformMovingMedian = "moving_median"
+	// TransformRate represents a non-negative per second rate transform type.
+	TransformRate = "rate"
+)
+
+// Transform represents a series transform instance.
+type Transform struct {
+	Type  string
+	Param float64
+}
+
+// ParseTransform parses a transform definition, having either the "type" or "type:param" form:
+//
+//   * abs: absolute value
+//   * derivative: difference between consecutive points
+//   * integral: cumulative sum of points
+//   * log[:base]: logarithm of points (default base: 10)
+//   * moving_average:n: average over the n last points
+//   * moving_median:n: median over the n last points
+//   * rate[:max]: non-negative per second rate, handling counter wrapping at max value if provided
+func ParseTransform(s string) (Transform, error) {
+	var err error
+
+	t := Transform{}
+
+	idx := strings.Index(s, ":")
+	if idx == -1 {
+		t.Type = s
+	} else {
+		t.Type = s[:idx]
+
+		t.Param, err = strconv.ParseFloat(s[idx+1:], 64)
+		if err != nil {
+			return t, fmt.Errorf("invalid %q transform parameter", t.Type)
+		}
+	}
+
+	switch t.Type {
+	case TransformAbs, TransformDerivative, TransformIntegral:
+		if idx != -1 {
+			return t, fmt.Errorf("unexpected %q transform parameter", t.Type)
+		}
+
+	case TransformLog:
+		if idx == -1 {
+			t.Param = 10
+		} else if t.Param <= 0 || t.Param == 1 {
+			return t, fmt.Errorf("invalid %q transform parameter", t.Type)
+		}
+
+	case TransformMovingAverage, TransformMovingMedian:
+		if t.Param < 1 || t.Param != math.Trunc(t.Param) {
+			return t, fmt.Errorf("invalid %q transform parameter", t.Type)
+		}
+
+	case TransformRate:
+		if t.Param < 0 {
+			return t, fmt.Errorf("invalid %q transform parameter", t.Type)
+		}
+
+	default:
+		return t, fmt.Errorf("unsupported %q transform", t.Type)
+	}
+
+	return t, nil
+}
+
+// ParseTransforms parses a list of transform definitions, as found in series and groups options.
+func ParseTransforms(v interface{}) ([]Transform, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid transforms list")
+	}
+
+	result := []Transform{}
+	for _, entry := range list {
+		s, ok := entry.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid transform %v", entry)
+		}
+
+		t, err := ParseTransform(s)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// Transform applies a transform on a series of points.
+func (s *Series) Transform(t Transform) {
+	switch t.Type {
+	case TransformAbs:
+		s.apply(math.Abs)
+
+	case TransformDerivative:
+		s.derivative(false, 0)
+
+	case TransformIntegral:
+		s.integral()
+
+	case TransformLog:
+		base := math.Log(t.Param)
+		s.apply(func(v float64) float64 {
+			if v <= 0 {
+				return math.NaN()
+			}
+			return math.Log(v) / base
+		})
+
+	case TransformMovingAverage:
+		s.moving(int(t.Param), func(values []float64) float64 {
+			sum := 0.0
+			for _, v := range values {
+				sum += v
+			}
+			return sum / float64(len(values))
+		})
+
+	case TransformMovingMedian:
+		s.moving(int(t.Param), median)
+
+	case TransformRate:
+		s.derivative(true, t.Param)
+	}
+}
+
+func (s *Series) apply(fn func(float64) float64) {
+	for i := range s.Points {
+		if !s.Points[i].Value.IsNaN() {
+			s.Points[i].Value = Value(fn(float64(s.Points[i].Value)))
+		}
+	}
+}
+
+func (s *Series) derivative(rate bool, max float64) {
+	prev := Value(math.NaN())
+
+	for i := range s.Points {
+		current := s.Points[i].Value
+
+		if i == 0 || current.IsNaN() || prev.IsNaN() {
+			s.Points[i].Value = Value(math.NaN())
+			prev = current
+			continue
+		}
+
+		delta := float64(current - prev)
+
+		if rate {
+			// Handle counter wrapping if maximal value is known, otherwise consider it as a counter reset
+			if delta < 0 && max > 0 && float64(prev) <= max {
+				delta += max + 1
+			} else if delta < 0 {
+				delta = math.NaN()
+			}
+
+			if seconds := s.Points[i].Time.Sub(s.Points[i-1].Time).Seconds(); seconds > 0 {
+				delta /= seconds
+			} else {
+				delta = math.NaN()
+			}
+		}
+
+		s.Points[i].Value = Value(delta)
+		prev = current
+	}
+}
+
+func (s *Series) integral() {
+	sum := 0.0
+
+	for i := range s.Points {
+		if !s.Points[i].Value.IsNaN() {
+			sum += float64(s.Points[i].Value)
+			s.Points[i].Value = Value(sum)
+		}
+	}
+}
+
+func (s *Series) moving(n int, fn func([]float64) float64) {
+	values := make([]float64, len(s.Points))
+	for i := range s.Points {
+		values[i] = float64(s.Points[i].Value)
+	}
+
+	window := make([]float64, 0, n)
+
+	for i := range s.Points {
+		window = window[:0]
+		for j := i - n + 1; j <= i; j++ {
+			if j >= 0 && !math.IsNaN(values[j]) {
+				window = append(window, values[j])
+			}
+		}
+
+		if len(window) == 0 {
+			s.Points[i].Value = Value(math.NaN())
+			continue
+		}
+
+		s.Points[i].Value = Value(fn(window))
+	}
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 0 {
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	return sorted[n/2]
+}
@@ -0,0 +1,94 @@
+package series
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseTransform(t *testing.T) {
+	for s, expected := range map[string]Transform{
+		"abs":              {Type: TransformAbs},
+		"log":              {Type: TransformLog, Param: 10},
+		"log:2":            {Type: TransformLog, Param: 2},
+		"moving_average:5": {Type: TransformMovingAverage, Param: 5},
+		"rate":             {Type: TransformRate},
+		"rate:4294967295":  {Type: TransformRate, Param: 4294967295},
+	} {
+		transform, err := ParseTransform(s)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, transform)
+	}
+
+	for _, s := range []string{"unknown", "abs:1", "log:1", "moving_median", "moving_median:0", "moving_average:x"} {
+		_, err := ParseTransform(s)
+		assert.NotNil(t, err, s)
+	}
+
+	transforms, err := ParseTransforms([]interface{}{"rate", "moving_average:3"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Transform{{Type: TransformRate}, {Type: TransformMovingAverage, Param: 3}}, transforms)
+
+	_, err = ParseTransforms([]interface{}{"rate", 3})
+	assert.NotNil(t, err)
+}
+
+func Test_Transform_Abs(t *testing.T) {
+	testTransform(t, "abs", []Value{-1, 2, nan, -0.5}, []Value{1, 2, nan, 0.5})
+}
+
+func Test_Transform_Derivative(t *testing.T) {
+	testTransform(t, "derivative", []Value{1, 3, 6, nan, 8, 4}, []Value{nan, 2, 3, nan, nan, -4})
+}
+
+func Test_Transform_Integral(t *testing.T) {
+	testTransform(t, "integral", []Value{1, 3, nan, 6}, []Value{1, 4, nan, 10})
+}
+
+func Test_Transform_Log(t *testing.T) {
+	testTransform(t, "log", []Value{1, 100, 0, -1, nan}, []Value{0, 2, nan, nan, nan})
+	testTransform(t, "log:2", []Value{8}, []Value{3})
+}
+
+func Test_Transform_MovingAverage(t *testing.T) {
+	testTransform(t, "moving_average:3", []Value{3, 6, nan, 9, 12, nan, nan, nan},
+		[]Value{3, 4.5, 4.5, 7.5, 10.5, 10.5, 12, nan})
+}
+
+func Test_Transform_MovingMedian(t *testing.T) {
+	testTransform(t, "moving_median:3", []Value{3, 1, 2, 10, 11}, []Value{3, 2, 2, 2, 10})
+}
+
+func Test_Transform_Rate(t *testing.T) {
+	// Points are 10 seconds apart
+	testTransform(t, "rate", []Value{100, 200, 350, 50, 150, nan, 200}, []Value{nan, 10, 15, nan, 10, nan, nan})
+	testTransform(t, "rate:4294967295", []Value{4294967000, 4294967200, 104}, []Value{nan, 20, 20})
+}
+
+var nan = Value(math.NaN())
+
+func testTransform(t *testing.T, s string, input, expected []Value) {
+	transform, err := ParseTransform(s)
+	if err != nil {
+		t.Fatalf("failed to parse transform: %s", err)
+	}
+
+	startTime := time.Unix(1500000000, 0)
+
+	series := Series{}
+	for i, v := range input {
+		series.Points = append(series.Points, Point{Time: startTime.Add(time.Duration(i) * 10 * time.Second), Value: v})
+	}
+
+	series.Transform(transform)
+
+	for i, p := range series.Points {
+		if expected[i].IsNaN() {
+			assert.True(t, p.Value.IsNaN(), "%s: point #%d: expected NaN but got %v", s, i, p.Value)
+		} else {
+			assert.InDelta(t, float64(expected[i]), float64(p.Value), 1e-9, "%s: point #%d", s, i)
+		}
+	}
+}
@@ -188,6 +188,7 @@ type SeriesGroup struct {
 	Name        string      `json:"name"`
 	Operator    int         `json:"operator"`
 	Consolidate int         `json:"consolidate"`
+	Expression  string      `json:"expression,omitempty"`
 	Series      []*Series   `json:"series"`
 	Options     maputil.Map `json:"options,omitempty"`
 }
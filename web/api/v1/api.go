@@ -64,6 +64,26 @@ func NewAPI(
 	root.Endpoint("/catalog/:type/*").
 		Get(api.catalogGet)
 
+	root.Endpoint("/grafana/").
+		Get(api.grafanaTest)
+	root.Endpoint("/grafana/annotations").
+		Post(api.grafanaAnnotations)
+	root.Endpoint("/grafana/query").
+		Post(api.grafanaQuery)
+	root.Endpoint("/grafana/search").
+		Post(api.grafanaSearch)
+	root.Endpoint("/grafana/tag-keys").
+		Post(api.grafanaTagKeys)
+	root.Endpoint("/grafana/tag-values").
+		Post(api.grafanaTagValues)
+
+	root.Endpoint("/graphite/metrics/find").
+		Get(api.graphiteFind).
+		Post(api.graphiteFind)
+	root.Endpoint("/graphite/render").
+		Get(api.graphiteRender).
+		Post(api.graphiteRender)
+
 	root.Endpoint("/library").
 		Get(api.librarySummary)
 	root.Endpoint("/library/parse").
@@ -94,10 +114,17 @@ func NewAPI(
 	root.Endpoint("/providers/:id/refresh").
 		Post(api.providerRefresh)
 
+	root.Endpoint("/render/graphs/:id").
+		Get(api.renderGraph)
+
 	root.Endpoint("/series/expand").
 		Post(api.seriesExpand)
+	root.Endpoint("/series/histogram").
+		Post(api.seriesHistogram)
 	root.Endpoint("/series/points").
 		Post(api.seriesPoints)
+	root.Endpoint("/series/stream").
+		Post(api.seriesStream)
 
 	root.Endpoint("/version").
 		Get(api.versionGet)
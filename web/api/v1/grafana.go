@@ -0,0 +1,398 @@
+package v1
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"facette.io/facette/grafana"
+	"facette.io/facette/series"
+	"facette.io/facette/storage"
+	"facette.io/httputil"
+	"facette.io/sqlstorage"
+)
+
+const grafanaGraphPrefix = "graph:"
+
+var grafanaTagKeys = []string{"origin", "source", "metric"}
+
+// api:method GET /api/v1/grafana/ "Check Grafana datasource"
+//
+// This endpoint is used by the Grafana JSON datasource plugin to test the datasource connection, the datasource URL
+// being set to `/api/v1/grafana`.
+//
+// ---
+// section: grafana
+// responses:
+//   200:
+func (a *API) grafanaTest(rw http.ResponseWriter, r *http.Request) {
+	rw.WriteHeader(http.StatusOK)
+}
+
+// api:method POST /api/v1/grafana/search "Search Grafana targets"
+//
+// This endpoint returns the targets matching a Grafana JSON datasource search request `target` element. Targets are
+// either:
+//
+//   * catalog metrics paths (as supported by the `/api/v1/graphite/metrics/find` endpoint), if the requested target
+//     is a metric path pattern (default: `*`)
+//   * stored graphs, prefixed by `graph:` and followed by their alias or identifier, if the requested target starts
+//     with `graph:` (the remaining part filtering graphs names)
+//
+// ---
+// section: grafana
+// request:
+//   type: object
+//   examples:
+//   - format: javascript
+//     headers:
+//       Content-Type: application/json
+//     body: |
+//       {
+//         "target": "graph:load"
+//       }
+// responses:
+//   200:
+//     type: array
+//     examples:
+//     - format: javascript
+//       body: |
+//         [
+//           {
+//             "text": "host1.example.net - Load average",
+//             "value": "graph:host1-load"
+//           }
+//         ]
+func (a *API) grafanaSearch(rw http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	req := grafana.SearchRequest{}
+	if err := httputil.BindJSON(r, &req); err == httputil.ErrInvalidContentType {
+		httputil.WriteJSON(rw, newMessage(err), http.StatusUnsupportedMediaType)
+		return
+	} else if err != nil {
+		a.logger.Error("unable to unmarshal JSON data: %s", err)
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	result := []grafana.SearchResult{}
+
+	if strings.HasPrefix(req.Target, grafanaGraphPrefix) {
+		filters := map[string]interface{}{"template": false}
+		if v := strings.TrimPrefix(req.Target, grafanaGraphPrefix); v != "" {
+			filters["name"] = sqlstorage.GlobModifier("*" + v + "*")
+		}
+
+		graphs := []*storage.Graph{}
+		if _, err := a.storage.SQL().List(&graphs, filters, []string{"name"}, 0, 0, false); err != nil {
+			a.logger.Error("failed to fetch items: %s", err)
+			httputil.WriteJSON(rw, newMessage(errUnhandledError), http.StatusInternalServerError)
+			return
+		}
+
+		for _, g := range graphs {
+			id := g.ID
+			if g.Alias != nil && *g.Alias != "" {
+				id = *g.Alias
+			}
+
+			result = append(result, grafana.SearchResult{Text: g.Name, Value: grafanaGraphPrefix + id})
+		}
+	} else {
+		if req.Target == "" {
+			req.Target = "*"
+		}
+
+		for _, node := range a.graphiteNodes(req.Target) {
+			result = append(result, grafana.SearchResult{Text: node.path, Value: node.path})
+		}
+	}
+
+	httputil.WriteJSON(rw, result, http.StatusOK)
+}
+
+// api:method POST /api/v1/grafana/query "Query Grafana targets"
+//
+// This endpoint retrieves data points for the targets of a Grafana JSON datasource query request, as returned by the
+// `/api/v1/grafana/search` endpoint. Stored graphs targets (`graph:` prefixed) return all of the graph's series as
+// computed by the `/api/v1/series/points` endpoint, whereas metrics paths targets return each matching catalog
+// metric. Series are normalized to the request `maxDataPoints` element if set.
+//
+// Ad hoc filters (having `origin`, `source` or `metric` keys) apply to metrics paths targets only.
+//
+// Only the `timeserie` response format is supported, targets being returned in request order.
+//
+// ---
+// section: grafana
+// request:
+//   type: object
+//   examples:
+//   - format: javascript
+//     headers:
+//       Content-Type: application/json
+//     body: |
+//       {
+//         "range": {
+//           "from": "2017-06-07T12:28:08Z",
+//           "to": "2017-06-07T12:29:08Z"
+//         },
+//         "targets": [
+//           {"target": "graph:host1-load", "refId": "A", "type": "timeserie"}
+//         ],
+//         "maxDataPoints": 3
+//       }
+// responses:
+//   200:
+//     type: array
+//     examples:
+//     - format: javascript
+//       body: |
+//         [
+//           {
+//             "target": "shortterm",
+//             "refId": "A",
+//             "datapoints": [
+//               [0.42, 1496838488000],
+//               [0.4, 1496838508000],
+//               [0.39, 1496838528000]
+//             ]
+//           }
+//         ]
+func (a *API) grafanaQuery(rw http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	req := grafana.QueryRequest{}
+	if err := httputil.BindJSON(r, &req); err == httputil.ErrInvalidContentType {
+		httputil.WriteJSON(rw, newMessage(err), http.StatusUnsupportedMediaType)
+		return
+	} else if err != nil {
+		a.logger.Error("unable to unmarshal JSON data: %s", err)
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	if req.Range.From.IsZero() || !req.Range.To.After(req.Range.From) {
+		httputil.WriteJSON(rw, newMessage(errInvalidTimerange), http.StatusBadRequest)
+		return
+	} else if req.MaxDataPoints < 0 {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	for _, f := range req.AdhocFilters {
+		if _, err := f.Match(""); err != nil {
+			httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+			return
+		}
+	}
+
+	result := []grafana.Series{}
+
+	for _, target := range req.Targets {
+		if target.Hide || target.Target == "" {
+			continue
+		}
+
+		pointsReq := &series.Request{
+			StartTime: req.Range.From,
+			EndTime:   req.Range.To,
+			Sample:    req.MaxDataPoints,
+		}
+
+		if strings.HasPrefix(target.Target, grafanaGraphPrefix) {
+			pointsReq.ID = strings.TrimPrefix(target.Target, grafanaGraphPrefix)
+			if !a.prepareRequest(rw, pointsReq) {
+				return
+			}
+		} else {
+			pointsReq.Graph = a.storage.NewGraph()
+			pointsReq.Graph.Groups = storage.SeriesGroups{a.grafanaGroup(target.Target, req.AdhocFilters)}
+
+			if pointsReq.Sample == 0 {
+				pointsReq.Sample = series.DefaultSample
+			}
+		}
+
+		for _, s := range a.executeRequest(r.Context(), pointsReq, req.MaxDataPoints > 0) {
+			if s.Status.Code != series.StatusOK {
+				continue
+			}
+
+			entry := grafana.Series{
+				Target:     s.Name,
+				RefID:      target.RefID,
+				Datapoints: make([]grafana.Datapoint, len(s.Points)),
+			}
+
+			for i, p := range s.Points {
+				entry.Datapoints[i] = grafana.Datapoint{Time: p.Time, Value: p.Value}
+			}
+
+			result = append(result, entry)
+		}
+	}
+
+	httputil.WriteJSON(rw, result, http.StatusOK)
+}
+
+// api:method POST /api/v1/grafana/annotations "Query Grafana annotations"
+//
+// This endpoint is required by the Grafana JSON datasource plugin. As no annotations are stored, it always returns
+// an empty array.
+//
+// ---
+// section: grafana
+// responses:
+//   200:
+//     type: array
+//     examples:
+//     - format: javascript
+//       body: |
+//         []
+func (a *API) grafanaAnnotations(rw http.ResponseWriter, r *http.Request) {
+	httputil.WriteJSON(rw, []interface{}{}, http.StatusOK)
+}
+
+// api:method POST /api/v1/grafana/tag-keys "List Grafana ad hoc filters keys"
+//
+// This endpoint returns the keys available for Grafana ad hoc filters: `origin`, `source` and `metric`.
+//
+// ---
+// section: grafana
+// responses:
+//   200:
+//     type: array
+//     examples:
+//     - format: javascript
+//       body: |
+//         [
+//           {"type": "string", "text": "origin"},
+//           {"type": "string", "text": "source"},
+//           {"type": "string", "text": "metric"}
+//         ]
+func (a *API) grafanaTagKeys(rw http.ResponseWriter, r *http.Request) {
+	result := []grafana.TagKey{}
+	for _, key := range grafanaTagKeys {
+		result = append(result, grafana.TagKey{Type: "string", Text: key})
+	}
+
+	httputil.WriteJSON(rw, result, http.StatusOK)
+}
+
+// api:method POST /api/v1/grafana/tag-values "List Grafana ad hoc filters values"
+//
+// This endpoint returns the catalog values available for a Grafana ad hoc filter `key` (either `origin`, `source` or
+// `metric`).
+//
+// ---
+// section: grafana
+// request:
+//   type: object
+//   examples:
+//   - format: javascript
+//     headers:
+//       Content-Type: application/json
+//     body: |
+//       {
+//         "key": "origin"
+//       }
+// responses:
+//   200:
+//     type: array
+//     examples:
+//     - format: javascript
+//       body: |
+//         [
+//           {"text": "collectd"},
+//           {"text": "graphite"}
+//         ]
+func (a *API) grafanaTagValues(rw http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	req := grafana.TagValuesRequest{}
+	if err := httputil.BindJSON(r, &req); err == httputil.ErrInvalidContentType {
+		httputil.WriteJSON(rw, newMessage(err), http.StatusUnsupportedMediaType)
+		return
+	} else if err != nil {
+		a.logger.Error("unable to unmarshal JSON data: %s", err)
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	values := make(map[string]struct{})
+
+	switch req.Key {
+	case "origin":
+		for _, o := range a.searcher.Origins("") {
+			values[o.Name] = struct{}{}
+		}
+
+	case "source":
+		for _, s := range a.searcher.Sources("", "") {
+			values[s.Name] = struct{}{}
+		}
+
+	case "metric":
+		for _, m := range a.searcher.Metrics("", "", "") {
+			values[m.Name] = struct{}{}
+		}
+
+	default:
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]grafana.TagValue, len(names))
+	for i, name := range names {
+		result[i] = grafana.TagValue{Text: name}
+	}
+
+	httputil.WriteJSON(rw, result, http.StatusOK)
+}
+
+// grafanaGroup returns a series group having the catalog metrics matching a metric path pattern and ad hoc filters.
+func (a *API) grafanaGroup(target string, filters []grafana.Filter) *storage.SeriesGroup {
+	group := &storage.SeriesGroup{Name: target}
+
+nodes:
+	for _, node := range a.graphiteNodes(target) {
+		if !node.leaf {
+			continue
+		}
+
+		for _, f := range filters {
+			var value string
+
+			switch f.Key {
+			case "origin":
+				value = node.origin
+			case "source":
+				value = node.source
+			case "metric":
+				value = node.metric
+			default:
+				continue
+			}
+
+			if ok, _ := f.Match(value); !ok {
+				continue nodes
+			}
+		}
+
+		group.Series = append(group.Series, &storage.Series{
+			Name:   node.path,
+			Origin: node.origin,
+			Source: node.source,
+			Metric: node.metric,
+		})
+	}
+
+	return group
+}
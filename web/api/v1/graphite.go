@@ -0,0 +1,266 @@
+package v1
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"facette.io/facette/graphite"
+	"facette.io/facette/series"
+	"facette.io/facette/storage"
+	"facette.io/httputil"
+)
+
+const defaultGraphiteFrom = "-24h"
+
+// graphiteNode represents a catalog entry mapped onto a Graphite metric path.
+type graphiteNode struct {
+	path   string
+	leaf   bool
+	origin string
+	source string
+	metric string
+}
+
+// api:method GET /api/v1/graphite/metrics/find "Find Graphite metrics paths"
+//
+// This endpoint implements the Graphite `/metrics/find` protocol, allowing Graphite clients (e.g. Grafana) to browse
+// the catalog. Catalog entries are mapped onto `origin.source.metric` paths, dots in entries names being replaced by
+// underscores (e.g. origin `host1.example.net` being mapped onto the `host1_example_net` path node). Entries names
+// only differing by dots and underscores (e.g. `a.b` and `a_b`) are thus mapped onto the same path, in which case only
+// the first entry (in catalog order) is reachable, a warning being logged for the other ones.
+//
+// The `query` parameter (either from the query string or form-encoded body) is a metric path pattern, each of its
+// nodes supporting the `*`, `?` and `[...]` wildcards along with `{a,b}` alternatives.
+//
+// ---
+// section: graphite
+// parameters:
+// - name: query
+//   type: string
+//   description: metric path pattern
+//   in: query
+//   required: true
+// responses:
+//   200:
+//     type: array
+//     examples:
+//     - format: javascript
+//       body: |
+//         [
+//           {
+//             "id": "host1_example_net.cpu_percent",
+//             "text": "cpu_percent",
+//             "leaf": 0,
+//             "expandable": 1,
+//             "allowChildren": 1
+//           }
+//         ]
+func (a *API) graphiteFind(rw http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	query := r.Form.Get("query")
+	if query == "" {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	result := []graphite.Node{}
+	for _, node := range a.graphiteNodes(query) {
+		result = append(result, graphite.NewNode(node.path, node.leaf))
+	}
+
+	httputil.WriteJSON(rw, result, http.StatusOK)
+}
+
+// api:method GET /api/v1/graphite/render "Render Graphite targets"
+//
+// This endpoint implements the Graphite `/render` protocol (JSON format only), allowing Graphite clients to retrieve
+// data points of catalog metrics through their respective providers. Requests can either be `GET` or form-encoded
+// `POST` ones, with the following parameters:
+//
+//   * `target` (type _string_, repeatable): metric path pattern, as supported by the `/api/v1/graphite/metrics/find`
+//     endpoint (Graphite functions are not supported)
+//   * `from` (type _string_, default `"-24h"`): time span start bound
+//   * `until` (type _string_, default `"now"`): time span end bound
+//   * `maxDataPoints` (type _integer_): maximum number of data points per series, series being consolidated using
+//     average if set
+//   * `format` (type _string_, default `"json"`): response format, only `json` being supported
+//
+// Time bounds can be set as `now`, Unix timestamps, relative offsets (e.g. `-1h`, `-30min`, `-7d`, `now-1w`),
+// `HH:MM_YYYYMMDD` or `YYYYMMDD` values.
+//
+// The response is an array of series, each having a `target` field set to the resolved metric path and a
+// `datapoints` field listing `[value, timestamp]` pairs.
+//
+// ---
+// section: graphite
+// responses:
+//   200:
+//     type: array
+//     examples:
+//     - format: javascript
+//       body: |
+//         [
+//           {
+//             "target": "host1_example_net.cpu_percent.idle",
+//             "datapoints": [
+//               [97.2, 1496838488],
+//               [96.8, 1496838548],
+//               [null, 1496838608]
+//             ]
+//           }
+//         ]
+func (a *API) graphiteRender(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	if err = r.ParseForm(); err != nil {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	if format := r.Form.Get("format"); format != "" && format != "json" {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	req := &series.Request{
+		Graph:  a.storage.NewGraph(),
+		Sample: series.DefaultSample,
+	}
+
+	// Set request time boundaries
+	now := time.Now().UTC()
+
+	from := r.Form.Get("from")
+	if from == "" {
+		from = defaultGraphiteFrom
+	}
+
+	req.StartTime, err = graphite.ParseTime(from, now)
+	if err == nil {
+		req.EndTime, err = graphite.ParseTime(r.Form.Get("until"), now)
+	}
+
+	if err != nil || !req.EndTime.After(req.StartTime) {
+		httputil.WriteJSON(rw, newMessage(errInvalidTimerange), http.StatusBadRequest)
+		return
+	}
+
+	// Normalize series if a maximum number of points is requested
+	if v := r.Form.Get("maxDataPoints"); v != "" {
+		if req.Sample, err = strconv.Atoi(v); err != nil || req.Sample <= 0 {
+			httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+			return
+		}
+
+		req.Normalize = true
+	}
+
+	// Resolve targets, mapping each of them onto a graph group
+	for _, target := range r.Form["target"] {
+		group := &storage.SeriesGroup{Name: target}
+		for _, node := range a.graphiteNodes(target) {
+			if !node.leaf {
+				continue
+			}
+
+			group.Series = append(group.Series, &storage.Series{
+				Name:   node.path,
+				Origin: node.origin,
+				Source: node.source,
+				Metric: node.metric,
+			})
+		}
+
+		if len(group.Series) > 0 {
+			req.Graph.Groups = append(req.Graph.Groups, group)
+		}
+	}
+
+	result := []graphite.Series{}
+	for _, s := range a.executeRequest(r.Context(), req, req.Normalize) {
+		if s.Status.Code != series.StatusOK {
+			continue
+		}
+
+		entry := graphite.Series{
+			Target:     s.Name,
+			Datapoints: make([]graphite.Datapoint, len(s.Points)),
+		}
+
+		for i, p := range s.Points {
+			entry.Datapoints[i] = graphite.Datapoint{Time: p.Time, Value: p.Value}
+		}
+
+		result = append(result, entry)
+	}
+
+	httputil.WriteJSON(rw, result, http.StatusOK)
+}
+
+// graphiteNodes returns the catalog entries matching a Graphite metric path pattern, ordered by path.
+func (a *API) graphiteNodes(query string) []graphiteNode {
+	patterns := graphite.Split(query)
+	if len(patterns) > 3 {
+		return nil
+	}
+
+	result := []graphiteNode{}
+
+	// Keep track of the entry name each path has been mapped from, as the same entry can be served by multiple
+	// catalogs (already ordered by priority) and escaping can map distinct entries names onto the same path
+	seen := make(map[string]string)
+
+	skip := func(path, kind, name string) bool {
+		v, ok := seen[path]
+		if !ok {
+			seen[path] = name
+			return false
+		} else if v != name {
+			a.logger.Warning("%s %q maps onto Graphite path %q already used by %q, skipping", kind, name, path, v)
+		}
+		return true
+	}
+
+	for _, o := range a.searcher.Origins("") {
+		originPath := graphite.Escape(o.Name)
+		if !graphite.Match(patterns[0], originPath) || skip(originPath, "origin", o.Name) {
+			continue
+		} else if len(patterns) == 1 {
+			result = append(result, graphiteNode{path: originPath, origin: o.Name})
+			continue
+		}
+
+		for _, s := range a.searcher.Sources(o.Name, "") {
+			sourcePath := originPath + "." + graphite.Escape(s.Name)
+			if !graphite.Match(patterns[1], graphite.Escape(s.Name)) || skip(sourcePath, "source", s.Name) {
+				continue
+			} else if len(patterns) == 2 {
+				result = append(result, graphiteNode{path: sourcePath, origin: o.Name, source: s.Name})
+				continue
+			}
+
+			for _, m := range a.searcher.Metrics(o.Name, s.Name, "") {
+				metricPath := sourcePath + "." + graphite.Escape(m.Name)
+				if graphite.Match(patterns[2], graphite.Escape(m.Name)) && !skip(metricPath, "metric", m.Name) {
+					result = append(result, graphiteNode{
+						path:   metricPath,
+						leaf:   true,
+						origin: o.Name,
+						source: s.Name,
+						metric: m.Name,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].path < result[j].path })
+
+	return result
+}
@@ -31,11 +31,18 @@ type Options struct {
 //       body: |
 //         {
 //           "connectors": [
+//             "elasticsearch",
+//             "exec",
 //             "facette",
 //             "graphite",
 //             "influxdb",
+//             "influxdb2",
 //             "kairosdb",
-//             "rrd"
+//             "opentsdb",
+//             "prometheus",
+//             "rrd",
+//             "sql",
+//             "whisper"
 //           ],
 //           "read_only": false
 //         }
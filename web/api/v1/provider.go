@@ -15,6 +15,37 @@ import (
 //
 // Catalog providers can be configured with settings and filters:
 //
+// ### Elasticsearch
+//
+// Also compatible with OpenSearch. Series are computed using a date histogram aggregation on the timestamp field.
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `url`<br>__required__ | string | URL of the Elasticsearch cluster |
+// | `index`<br>__required__ | string | index name or pattern to query documents from (e.g. `metrics-*`) |
+// | `source_field`<br>__required__ | string | field whose terms are mapped to sources |
+// | `origin_field` | string | field whose terms are mapped to origins (default: provider name) |
+// | `metric_fields` | array | numeric fields mapped to metrics (default: all numeric fields found in the index mapping) |
+// | `timestamp_field` | string | date field used for time ranges and histograms (default: `@timestamp`) |
+// | `aggregation` | string | aggregation applied to values within histogram intervals: `avg`, `max`, `min` or `sum` (default: `avg`) |
+// | `terms_size` | integer | maximum number of terms fetched per origin/source field during refresh (default: `1000`) |
+// | `username` | string | username used for basic authentication |
+// | `password` | string | password used for basic authentication |
+// | `timeout` | integer | delay in seconds before declaring a timeout (default: `10`) |
+// | `allow_insecure_tls` | boolean | allow invalid or expired SSL certificates when accessing the Elasticsearch API through HTTPS (default: `false`) |
+//
+// ### Exec
+//
+// Runs an external plugin program, exchanging JSON messages over its standard input/output (see
+// `docs/connector-exec.md` for the protocol description).
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `command`<br>__required__ | string | path of the plugin program to execute (looked up in `PATH` if not absolute) |
+// | `args` | array | arguments passed to the plugin program |
+// | `options` | object | free-form options passed to the plugin along with each request |
+// | `timeout` | integer | delay in seconds before killing the plugin when retrieving points (default: `10`) |
+//
 // ### Facette
 //
 // | Name | Type | Description |
@@ -63,6 +94,20 @@ import (
 //
 // Note: you should either use `pattern` or `mapping`, but not both.
 //
+// ### InfluxDB 2
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `url`<br>__required__ | string | URL of the InfluxDB instance |
+// | `token`<br>__required__ | string | InfluxDB API token used for authentication |
+// | `org`<br>__required__ | string | InfluxDB organization name |
+// | `bucket`<br>__required__ | string | InfluxDB bucket to query series from |
+// | `mapping` | object | measurements and tags to map the objects on (see _Mapping parameters_ in the InfluxDB section above, default: `{"source": ["column:host"], "metric": ["name"]}`). Fields are always appended to the *metric* name |
+// | `aggregate` | string | Flux aggregate function used for sampling (`count`, `first`, `last`, `max`, `mean`, `median`, `min` or `sum`, default: `mean`) |
+// | `discover_range` | string | Flux relative duration used to discover series (default: `-24h`) |
+// | `timeout` | integer | delay in seconds before declaring a timeout (default: `10`) |
+// | `allow_insecure_tls` | boolean | allow invalid or expired SSL certificates when accessing the InfluxDB API through HTTPS (default: `false`) |
+//
 // ### KairosDB
 //
 // | Name | Type | Description |
@@ -73,6 +118,49 @@ import (
 // | `timeout` | integer | delay in seconds before declaring a timeout (default: `10`) |
 // | `allow_insecure_tls` | boolean | allow invalid or expired SSL certificates when accessing the Facette API through HTTPS (default: `false`) |
 //
+// ### OpenTSDB
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `url`<br>__required__ | string | URL of the OpenTSDB instance (without the `/api` path) |
+// | `aggregators` | array of strings | OpenTSDB [aggregators](http://opentsdb.net/docs/build/html/user_guide/query/aggregators.html) to use for sampling (default: `["avg","max","min"]`) |
+// | `source_tags` | array of strings | OpenTSDB tags to look into for sources (default: `["host"]`) |
+// | `suggest_limit` | integer | maximum number of metrics to retrieve from the suggest API (default: `10000`) |
+// | `lookup_limit` | integer | maximum number of series to retrieve per metric from the lookup API (default: `25000`) |
+// | `timeout` | integer | delay in seconds before declaring a timeout (default: `10`) |
+// | `allow_insecure_tls` | boolean | allow invalid or expired SSL certificates when accessing the OpenTSDB API through HTTPS (default: `false`) |
+//
+// ### Prometheus
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `url`<br>__required__ | string | URL of the Prometheus instance (without the `/api` path) |
+// | `match` | array of strings | Prometheus [series selectors](https://prometheus.io/docs/prometheus/latest/querying/basics/#time-series-selectors) used to discover series (default: `["{__name__=~\".+\"}"]`) |
+// | `mapping` | object | labels to map the objects on (see _Mapping parameters_ below) |
+// | `timeout` | integer | delay in seconds before declaring a timeout (default: `10`) |
+// | `allow_insecure_tls` | boolean | allow invalid or expired SSL certificates when accessing the Prometheus API through HTTPS (default: `false`) |
+//
+// Mapping parameters:
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `origin` | array of strings | list of labels to map *origins* on (default: provider name) |
+// | `source` | array of strings | list of labels to map *sources* on (default: `["instance"]`) |
+// | `metric` | array of strings | list of labels to map *metrics* on (default: `["__name__"]`) |
+// | `glue` | string | separator used to join defined labels |
+//
+// ```javascript
+// {
+//   "origin": ["job"],
+//   "source": ["instance"],
+//   "metric": ["__name__", "device", "mode"],
+//   "glue": "."
+// }
+// ```
+//
+// Note: series sharing the same mapped *source* and *metric* are deduplicated in the catalog, make sure that the
+// mapping labels identify series uniquely.
+//
 // ### RRDtool
 //
 // | Name | Type | Description |
@@ -81,6 +169,39 @@ import (
 // | `pattern`<br>__required__ | string | regular expression (RE2 syntax) describing the pattern mapping *sources*/*metrics* to the filesystem structure under the base directory defined with the `path` setting. `<source>` and `<metric>` regexp named group are mandatory to effectively map a filesystem path to these objects |
 // | `daemon` | string | rrdcached daemon socket address, see `-l` option in `rrdcached(1)` manual for details |
 //
+// ### SQL
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `driver`<br>__required__ | string | database driver (`mysql`, `pgsql` or `sqlite`) |
+// | `dsn`<br>__required__ | string | database data source name, see driver documentation for the expected format |
+// | `catalog_query`<br>__required__ | string | SQL query returning the `source` and `metric` columns (and optionally `origin`) used to build the catalog |
+// | `points_query`<br>__required__ | string | SQL query returning time and value columns for a metric, see _Query parameters_ below |
+// | `epoch_time` | boolean | pass `:start` and `:end` parameters as UNIX timestamps instead of date/time values (default: `false`) |
+//
+// Query parameters:
+//
+// | Name | Description |
+// | --- | --- |
+// | `:start` | start time of the requested time span |
+// | `:end` | end time of the requested time span |
+// | `:origin` | origin name as returned by the catalog query |
+// | `:source` | source name as returned by the catalog query |
+// | `:metric` | metric name as returned by the catalog query |
+//
+// Parameters appearing inside quoted strings, quoted identifiers or comments are left untouched.
+//
+// ```sql
+// SELECT ts, depth FROM queues WHERE host = :source AND name = :metric AND ts BETWEEN :start AND :end ORDER BY ts
+// ```
+//
+// ### Whisper
+//
+// | Name | Type | Description |
+// | --- | --- | --- |
+// | `path`<br>__required__ | string | base path on the local filesystem where the Whisper files are stored |
+// | `pattern`<br>__required__ | string | regular expression (RE2 syntax) describing the pattern mapping *sources*/*metrics* to the filesystem structure under the base directory defined with the `path` setting. `<source>` and `<metric>` regexp named group are mandatory to effectively map a filesystem path to these objects |
+//
 // ## Provider Filters
 //
 // Provider filters allow changing how _sources_ and _metrics_ appear in the catalog, and discard the ones you don’t
@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"facette.io/facette/render"
+	"facette.io/facette/series"
+	"facette.io/httputil"
+	"github.com/vbatoufflet/httprouter"
+)
+
+const maxRenderSize = 4096
+
+// api:method GET /api/v1/render/graphs/:id "Render graph image"
+//
+// This endpoint renders an existing graph (either by its ID or alias) as an image, based on the same series
+// retrieval pipeline as the `/api/v1/series/points` endpoint, for embedding graphs where the UI can't be used (e.g.
+// emails, chat bots or wiki pages).
+//
+// Optional query parameters:
+//
+//   * `range` (type _string_, default graph `range` option): time offset relative to current time (e.g. `-1d`)
+//   * `width` (type _integer_, default `800`): image width in pixels
+//   * `height` (type _integer_, default `400`): image height in pixels
+//   * `format` (type _string_, default `"svg"`): image format, either `svg` or `png`
+//
+// If no `format` is specified, a PNG image is returned if the `Accept` request header contains `image/png`.
+//
+// The rendered graph honors the `title`, `type` (`line`, `area` or `stacked`), `stack_mode`, `yaxis_unit` and
+// `yaxis_unit_base` graph options, along with the `color` series option.
+//
+// ---
+// section: render
+// parameters:
+// - name: id
+//   type: string
+//   description: identifier or alias of the graph
+//   in: path
+//   required: true
+// responses:
+//   200:
+//     type: string
+//     examples:
+//     - format: text
+//       headers:
+//         Content-Type: image/svg+xml
+//       body: |
+//         <?xml version="1.0" encoding="UTF-8"?>
+//         <svg xmlns="http://www.w3.org/2000/svg" width="800" height="400" viewBox="0 0 800 400" ...>
+//         ...
+//         </svg>
+func (a *API) renderGraph(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	req := &series.Request{
+		ID:    httprouter.ContextParam(r, "id").(string),
+		Range: httprouter.QueryParam(r, "range"),
+	}
+
+	graph := render.Graph{}
+
+	graph.Width, err = parseIntParam(r, "width")
+	if err != nil || graph.Width < 0 || graph.Width > maxRenderSize {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	} else if graph.Width == 0 {
+		graph.Width = render.DefaultWidth
+	}
+
+	graph.Height, err = parseIntParam(r, "height")
+	if err != nil || graph.Height < 0 || graph.Height > maxRenderSize {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	} else if graph.Height == 0 {
+		graph.Height = render.DefaultHeight
+	}
+
+	// Get image format from query or "Accept" header
+	format := httprouter.QueryParam(r, "format")
+	if format == "" {
+		format = "svg"
+		if strings.Contains(r.Header.Get("Accept"), "image/png") {
+			format = "png"
+		}
+	} else if format != "svg" && format != "png" {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	// Sample one point per horizontal pixel at most
+	req.Sample = graph.Width
+
+	if !a.prepareRequest(rw, req) {
+		return
+	}
+
+	graph.StartTime = req.StartTime
+	graph.EndTime = req.EndTime
+
+	// Apply graph options
+	if v, ok := req.Graph.Options["title"].(string); ok {
+		graph.Title = v
+	} else {
+		graph.Title = req.Graph.Name
+	}
+
+	graph.Type = render.TypeArea
+	if v, ok := req.Graph.Options["type"].(string); ok {
+		graph.Type = v
+	}
+
+	if v, ok := req.Graph.Options["stack_mode"].(string); ok && v != "" {
+		graph.Type = render.TypeStacked
+	}
+
+	if v, ok := req.Graph.Options["yaxis_unit"].(string); ok {
+		graph.Unit = v
+	}
+
+	if v, ok := req.Graph.Options["yaxis_unit_base"].(string); ok {
+		graph.UnitBase = v
+	}
+
+	// Execute points request, normalizing series if stacked as they need to be aligned
+	for _, s := range a.executeRequest(r.Context(), req, req.Normalize || graph.Type == render.TypeStacked) {
+		entry := render.Series{
+			Name:   s.Name,
+			Points: s.Points,
+		}
+
+		if v, ok := s.Options["color"].(string); ok {
+			entry.Color = v
+		}
+
+		graph.Series = append(graph.Series, entry)
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	contentType := "image/svg+xml"
+	if format == "png" {
+		contentType = "image/png"
+		err = render.PNG(buf, graph)
+	} else {
+		err = render.SVG(buf, graph)
+	}
+
+	if err != nil {
+		a.logger.Error("failed to render graph: %s", err)
+		httputil.WriteJSON(rw, newMessage(errUnhandledError), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(buf.Bytes())
+}
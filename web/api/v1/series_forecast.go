@@ -0,0 +1,173 @@
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	"facette.io/facette/series"
+	"facette.io/facette/storage"
+	"facette.io/facette/timerange"
+)
+
+const (
+	defaultForecastHistory    = "-7d"
+	defaultForecastSeason     = "1d"
+	defaultForecastAlpha      = 0.1
+	defaultForecastBeta       = 0.0035
+	defaultForecastGamma      = 0.1
+	defaultForecastDeviations = 3.0
+	defaultAnomalyThreshold   = 3.0
+)
+
+type forecastOptions struct {
+	history    string
+	season     string
+	alpha      float64
+	beta       float64
+	gamma      float64
+	deviations float64
+	threshold  float64
+}
+
+// seriesForecast returns the forecast options of a series, or nil if forecasting isn't enabled for it. Series options
+// take precedence over group then graph ones.
+func seriesForecast(s *storage.Series, group *storage.SeriesGroup,
+	graphOptions map[string]interface{}) *forecastOptions {
+	// Forecasting is only available for series not being part of an operation
+	if group.Operator != series.OperatorNone {
+		return nil
+	}
+
+	options := []map[string]interface{}{s.Options, group.Options, graphOptions}
+
+	if enabled, _ := lookupOption("forecast", options...).(bool); !enabled {
+		return nil
+	}
+
+	fo := &forecastOptions{
+		history:    defaultForecastHistory,
+		season:     defaultForecastSeason,
+		alpha:      defaultForecastAlpha,
+		beta:       defaultForecastBeta,
+		gamma:      defaultForecastGamma,
+		deviations: defaultForecastDeviations,
+		threshold:  defaultAnomalyThreshold,
+	}
+
+	if v, ok := lookupOption("forecast_history", options...).(string); ok && v != "" {
+		fo.history = v
+	}
+
+	if v, ok := lookupOption("forecast_season", options...).(string); ok {
+		fo.season = v
+	}
+
+	for key, value := range map[string]*float64{
+		"forecast_alpha":      &fo.alpha,
+		"forecast_beta":       &fo.beta,
+		"forecast_gamma":      &fo.gamma,
+		"forecast_deviations": &fo.deviations,
+		"anomaly_threshold":   &fo.threshold,
+	} {
+		if v, ok := lookupOption(key, options...).(float64); ok {
+			*value = v
+		}
+	}
+
+	return fo
+}
+
+// forecastSeries computes forecast bands and anomalies of the group series having forecasting enabled, inserting them
+// right after their original series, and trims their training history points.
+func (a *API) forecastSeries(req *series.Request, group *storage.SeriesGroup, data []series.Series,
+	statuses []series.Status, consolidate int, consolidatePct float64) ([]series.Series, []series.Status) {
+	groupSeries := []*storage.Series{}
+	resultData := []series.Series{}
+	resultStatuses := []series.Status{}
+
+	step := req.EndTime.Sub(req.StartTime) / time.Duration(req.Sample)
+
+	for j, s := range group.Series {
+		groupSeries = append(groupSeries, s)
+		resultData = append(resultData, data[j])
+		resultStatuses = append(resultStatuses, statuses[j])
+
+		fo := seriesForecast(s, group, req.Graph.Options)
+		if fo == nil || statuses[j].Code != series.StatusOK || step <= 0 {
+			continue
+		}
+
+		// Normalize series over its whole training history, keeping the requested time step
+		startTime, err := timerange.Apply(req.StartTime, fo.history)
+		if err != nil || !startTime.Before(req.StartTime) {
+			a.logger.Warning("invalid forecast history: %s", fo.history)
+			continue
+		}
+
+		normalized, err := series.Normalize([]series.Series{data[j]}, startTime, req.EndTime,
+			int(req.EndTime.Sub(startTime)/step), consolidate, consolidatePct, series.FillNull)
+		if err != nil {
+			a.logger.Error("failed to normalize series: %s", err)
+			continue
+		}
+
+		season := 0
+		if fo.season != "" {
+			seasonEnd, err := timerange.Apply(startTime, fo.season)
+			if err != nil {
+				a.logger.Warning("invalid forecast season: %s", fo.season)
+			} else {
+				season = int(seasonEnd.Sub(startTime) / step)
+			}
+		}
+
+		forecast, upper, lower := series.HoltWinters(normalized[0], season, fo.alpha, fo.beta, fo.gamma,
+			fo.deviations)
+		anomalies := series.Anomalies(normalized[0], fo.threshold)
+
+		// Trim training history points and append forecast series
+		resultData[len(resultData)-1] = trimSeries(normalized[0], req.StartTime, req.EndTime)
+
+		for _, entry := range []struct {
+			name   string
+			series series.Series
+		}{
+			{"forecast", forecast},
+			{"upper", upper},
+			{"lower", lower},
+			{"anomalies", anomalies},
+		} {
+			groupSeries = append(groupSeries, &storage.Series{Name: fmt.Sprintf("%s (%s)", s.Name, entry.name)})
+			resultData = append(resultData, trimSeries(entry.series, req.StartTime, req.EndTime))
+			resultStatuses = append(resultStatuses, statuses[j])
+		}
+	}
+
+	group.Series = groupSeries
+
+	return resultData, resultStatuses
+}
+
+// trimSeries returns a new series only having the points located within a time span.
+func trimSeries(s series.Series, startTime, endTime time.Time) series.Series {
+	result := series.Series{Points: []series.Point{}}
+
+	for _, p := range s.Points {
+		if !p.Time.Before(startTime) && !p.Time.After(endTime) {
+			result.Points = append(result.Points, p)
+		}
+	}
+
+	return result
+}
+
+// lookupOption returns the first value found for a given key in a list of series, group or graph options.
+func lookupOption(key string, options ...map[string]interface{}) interface{} {
+	for _, o := range options {
+		if v, ok := o[key]; ok {
+			return v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"facette.io/facette/series"
+	"facette.io/httputil"
+)
+
+// api:method POST /api/v1/series/histogram "Retrieve series values distribution"
+//
+// This endpoint retrieves the distribution of all of a graph's series values for each time step (e.g. for rendering
+// heatmaps), based on the same query as the `/api/v1/series/points` endpoint along with the following optional
+// elements:
+//
+//   * `buckets` (type _integer_, default `10`): number of linear value buckets, covering all series values
+//   * `bounds` (type _array_): ascending value buckets bounds, overriding `buckets` if specified
+//
+// Series are normalized to the requested sample, the `drop` fill strategy being ignored as series have to remain
+// aligned. Value buckets include their lower bound, the last one also including its upper bound. When `bounds` is
+// specified, values out of bounds are discarded.
+//
+// The response has a `bounds` array listing the value buckets bounds, and a `steps` array having for each time step
+// its timestamp and the number of values found in each value bucket. The `partial` field is set to `true` if at least
+// one series failed to be retrieved.
+//
+// ---
+// section: series
+// request:
+//   type: object
+//   examples:
+//   - format: javascript
+//     headers:
+//       Content-Type: application/json
+//     body: |
+//       {
+//         "id": "c5e5faf1-dda1-50b3-abcb-4a5bdae7328e",
+//         "sample": 3,
+//         "range": "-3m",
+//         "bounds": [0, 100, 250, 500, 1000]
+//       }
+// responses:
+//   200:
+//     type: object
+//     examples:
+//     - format: javascript
+//       body: |
+//         {
+//           "start": "2017-06-07T12:26:08Z",
+//           "end": "2017-06-07T12:29:08Z",
+//           "bounds": [0, 100, 250, 500, 1000],
+//           "steps": [
+//             [1496838368, [12, 25, 3, 0]],
+//             [1496838428, [10, 24, 5, 1]],
+//             [1496838488, [14, 22, 4, 0]]
+//           ],
+//           "options": {
+//             "title": "Web servers - Response time"
+//           },
+//           "partial": false
+//         }
+func (a *API) seriesHistogram(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	defer r.Body.Close()
+
+	// Get histogram request from received data
+	req := &series.HistogramRequest{}
+	if err = httputil.BindJSON(r, req); err == httputil.ErrInvalidContentType {
+		httputil.WriteJSON(rw, newMessage(err), http.StatusUnsupportedMediaType)
+		return
+	} else if err != nil {
+		a.logger.Error("unable to unmarshal JSON data: %s", err)
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	// Check for value buckets validity
+	if req.Bounds != nil && (len(req.Bounds) < 2 || !sort.Float64sAreSorted(req.Bounds)) || req.Buckets < 0 {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	if !a.prepareRequest(rw, &req.Request) {
+		return
+	}
+
+	// Prevent null time steps from being dropped, as series must remain aligned
+	for _, group := range req.Graph.Groups {
+		if fill, _ := group.Options["fill"].(string); fill == series.FillDrop {
+			group.Options["fill"] = series.FillNull
+		}
+	}
+
+	histogram := series.HistogramResponse{
+		Start:   req.StartTime.Format(time.RFC3339),
+		End:     req.EndTime.Format(time.RFC3339),
+		Bounds:  req.Bounds,
+		Steps:   []series.HistogramStep{},
+		Options: req.Graph.Options,
+	}
+
+	// Execute normalized points request, skipping series not being in a valid state
+	data := []series.Series{}
+	for _, s := range a.executeRequest(r.Context(), &req.Request, true) {
+		if s.Status.Code != series.StatusOK {
+			histogram.Partial = true
+			continue
+		}
+
+		data = append(data, s.Series)
+	}
+
+	if histogram.Bounds == nil {
+		if req.Buckets == 0 {
+			req.Buckets = series.DefaultHistogramBuckets
+		}
+
+		histogram.Bounds = series.HistogramBounds(data, req.Buckets)
+	}
+
+	if len(data) > 0 {
+		if histogram.Steps, err = series.Histogram(data, histogram.Bounds); err != nil {
+			a.logger.Error("failed to compute histogram: %s", err)
+			httputil.WriteJSON(rw, newMessage(errUnhandledError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Set fallback title to graph name if none provided
+	if histogram.Options == nil {
+		histogram.Options = make(map[string]interface{})
+	}
+
+	if _, ok := histogram.Options["title"]; !ok {
+		histogram.Options["title"] = req.Graph.Name
+	}
+
+	httputil.WriteJSON(rw, histogram, http.StatusOK)
+}
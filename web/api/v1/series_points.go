@@ -1,9 +1,12 @@
 package v1
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"facette.io/facette/connector"
@@ -16,10 +19,13 @@ import (
 	"github.com/hashicorp/go-uuid"
 )
 
+const defaultPercentile = 95.0
+
 type pointQuery struct {
 	query     series.Query
 	queryMap  [][2]int
 	connector connector.Connector
+	offset    time.Duration
 }
 
 // api:method POST /api/v1/series/points "Retrieve series data points"
@@ -41,7 +47,80 @@ type pointQuery struct {
 //
 // Note: for absolute time span selection, both `start_end` and `end_time` values must be specified.
 //
-// The response is an array of graph series and their data points for the requested time span.
+// The response is an array of graph series and their data points for the requested time span. Providers are queried
+// concurrently: if one of them fails or times out, partial results are returned.
+//
+// Each series has a `status` object with a `code` field set to one of the following values, and an optional `message`
+// field describing the failure:
+//
+//   * `ok`: series data points successfully retrieved
+//   * `not_in_catalog`: series metric not found in catalog
+//   * `provider_error`: series provider failed or timed out retrieving data points
+//   * `invalid`: series definition is invalid (e.g. missing origin, source or metric, or invalid `transforms` option)
+//
+// The `partial` field is set to `true` if at least one series has a status code other than `ok`.
+//
+// Points can also be exported by setting the `format` element (or the `Accept` request header) to either `csv`
+// (`text/csv`) or `ndjson` (`application/x-ndjson`), along with the optional elements:
+//
+//   * `time_format` (type _string_, default `"rfc3339"`): exported timestamps format (either `rfc3339` or `epoch`)
+//   * `summary` (type _boolean_): append series summaries to exported points
+//
+// CSV exports have a `time` column followed by one column per series, null values being empty. Summaries are
+// appended after an empty record, each record first column being set to the summary name. NDJSON exports have a
+// first object having a `series` field listing series names, then one object per timestamp with `time` and `values`
+// (listing series values in the same order) fields, and a final object having a `summary` field (listing series
+// summaries in the same order) if requested.
+//
+// Series and groups can have a `timeshift` option (e.g. `-7d`, series option taking precedence over the group one),
+// querying providers over the shifted time span and re-aligning resulting data points onto the requested one. Shifted
+// series names are suffixed with the time shift value (e.g. `requests (-7d)`).
+//
+// Groups consolidation modes, used when normalizing series, are: `1` (average, default), `2` (first), `3` (last), `4`
+// (max), `5` (min), `6` (sum), `7` (median), `8` (percentile, set using the `consolidate_percentile` group option,
+// default: `95`) and `9` (non-null values count).
+//
+// Groups null values can be handled using the `fill` group option, triggering series normalization: `null` (default,
+// keep null values), `zero` (replace by zero), `previous` (replace by previous non-null value), `linear` (linear
+// interpolation between surrounding non-null values) or `drop` (remove time steps having null values in all of the
+// group series). Unlike the `zero` fill strategy, the `zero_nulls` group option only replaces null values returned by
+// providers by zero prior to normalization, and doesn't trigger normalization by itself.
+//
+// When all series are normalized, time steps having null values in all of them are removed from the response.
+//
+// Series summaries provide `min`, `max`, `avg` and `last` values, along with the percentiles listed in the
+// `percentiles` graph option. Additional statistics can be listed in the `summary_stats` graph option: `count`
+// (non-null values count), `delta` (last minus first value), `first`, `median`, `stddev` (standard deviation) and
+// `sum`.
+//
+// Groups having no operator can keep only their `top` or `bottom` N series (type _integer_), ranked by the `rank_by`
+// group option: `avg` (default), `max`, `min`, `last` or a percentile (e.g. `95th`). Remaining series are folded
+// into a single series named `other` (sum of their values) if the `other` group option is set to `true`.
+//
+// Series of groups having no operator can be forecasted by setting the `forecast` option to `true` (series options
+// taking precedence over group then graph ones). Providers are then queried over an extended time span for training
+// purpose, set using the `forecast_history` option (default: `-7d`), and an additive Holt-Winters model is applied
+// using the `forecast_season` (default: `1d`), `forecast_alpha` (default: `0.1`), `forecast_beta` (default: `0.0035`)
+// and `forecast_gamma` (default: `0.1`) options. Each forecasted series is followed in the response by the following
+// series:
+//
+//   * `<name> (forecast)`: forecast values
+//   * `<name> (upper)` and `<name> (lower)`: confidence bands, located at `forecast_deviations` (default: `3`)
+//     smoothed deviations from forecast values
+//   * `<name> (anomalies)`: values of the points whose z-score exceeds `anomaly_threshold` (default: `3`), other
+//     points being null
+//
+// Groups operators are: `0` (none), `1` (average), `2` (sum), `3` (expression), `4` (min), `5` (max), `6` (median),
+// `7` (percentile, set using the `percentile` group option, default: `95`), `8` (non-null values count) and `9`
+// (standard deviation).
+//
+// Groups having the expression operator (`3`) return a single series named after the group, computed by evaluating
+// their `expression` field (e.g. `errors / requests * 100`) on normalized series. Expressions can reference series by
+// name, either from the group itself or resulting from other groups, and support numeric constants, the `+`, `-`, `*`
+// and `/` operators, parentheses and the `abs`, `sqrt`, `log`, `min`, `max`, `sum` and `avg` functions. Series names
+// not being valid identifiers can be double-quoted. Referencing an unknown series results in an `invalid` status.
+// Groups having no operator are only normalized if some of their series are referenced by an expression, other ones
+// keeping their raw points.
 //
 // ---
 // section: series
@@ -87,14 +166,18 @@ type pointQuery struct {
 //                 "min": 576
 //               },
 //               "name": "lb1_example_net.current_connections",
-//               "options": null
+//               "options": null,
+//               "status": {
+//                 "code": "ok"
+//               }
 //             }
 //           ],
 //           "options": {
 //             "title": "lb1.example.net - Current connections",
 //             "type": "line",
 //             "yaxis_unit": "metric"
-//           }
+//           },
+//           "partial": false
 //         }
 func (a *API) seriesPoints(rw http.ResponseWriter, r *http.Request) {
 	var err error
@@ -112,6 +195,87 @@ func (a *API) seriesPoints(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Get export format from request or "Accept" header
+	if req.Format == "" {
+		req.Format = series.FormatJSON
+
+		if accept := r.Header.Get("Accept"); strings.Contains(accept, "text/csv") {
+			req.Format = series.FormatCSV
+		} else if strings.Contains(accept, "application/x-ndjson") {
+			req.Format = series.FormatNDJSON
+		}
+	}
+
+	if req.Format != series.FormatJSON && req.Format != series.FormatCSV && req.Format != series.FormatNDJSON ||
+		req.TimeFormat != "" && req.TimeFormat != series.TimeFormatRFC3339 && req.TimeFormat != series.TimeFormatEpoch {
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	if !a.prepareRequest(rw, req) {
+		return
+	}
+
+	// Execute points request
+	points := a.pointsResponse(r.Context(), req)
+
+	if req.Format == series.FormatJSON {
+		httputil.WriteJSON(rw, points, http.StatusOK)
+		return
+	}
+
+	// Export points using requested format
+	opts := series.ExportOptions{TimeFormat: req.TimeFormat, Summary: req.Summary}
+
+	if req.Format == series.FormatCSV {
+		rw.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		rw.WriteHeader(http.StatusOK)
+		err = points.WriteCSV(rw, opts)
+	} else {
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		rw.WriteHeader(http.StatusOK)
+		err = points.WriteNDJSON(rw, opts)
+	}
+
+	if err != nil {
+		a.logger.Error("failed to export points: %s", err)
+	}
+}
+
+// pointsResponse executes a series request and returns its points response.
+func (a *API) pointsResponse(ctx context.Context, req *series.Request) series.Response {
+	points := series.Response{
+		Start:   req.StartTime.Format(time.RFC3339),
+		End:     req.EndTime.Format(time.RFC3339),
+		Series:  a.executeRequest(ctx, req, req.Normalize),
+		Options: req.Graph.Options,
+	}
+
+	// Flag response as partial if any series isn't in a valid state
+	for _, s := range points.Series {
+		if s.Status.Code != series.StatusOK {
+			points.Partial = true
+			break
+		}
+	}
+
+	// Set fallback title to graph name if none provided
+	if points.Options == nil {
+		points.Options = make(map[string]interface{})
+	}
+
+	if _, ok := points.Options["title"]; !ok {
+		points.Options["title"] = req.Graph.Name
+	}
+
+	return points
+}
+
+// prepareRequest loads or expands the graph of a series request and sets its time boundaries and sample size. It
+// writes an error response and returns false if the request can't be satisfied.
+func (a *API) prepareRequest(rw http.ResponseWriter, req *series.Request) bool {
+	var err error
+
 	// Request item from storage
 	if req.ID != "" {
 		req.Graph = a.storage.NewGraph()
@@ -124,28 +288,28 @@ func (a *API) seriesPoints(rw http.ResponseWriter, r *http.Request) {
 
 		if err = a.storage.SQL().Get(column, req.ID, req.Graph, false); err == sqlstorage.ErrItemNotFound {
 			httputil.WriteJSON(rw, newMessage(err), http.StatusNotFound)
-			return
+			return false
 		} else if err != nil {
 			a.logger.Error("failed to fetch item: %s", err)
 			httputil.WriteJSON(rw, newMessage(errUnhandledError), http.StatusInternalServerError)
-			return
+			return false
 		}
 	} else if req.Graph != nil {
 		// Register storage (needed for graph expansion)
 		req.Graph.Item.SetStorage(a.storage)
 	} else {
 		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
-		return
+		return false
 	}
 
 	// Expand graph template if linked
 	if err = req.Graph.Expand(req.Attributes); req.ID == "" && err == template.ErrInvalidTemplate {
 		httputil.WriteJSON(rw, newMessage(err), http.StatusBadRequest)
-		return
+		return false
 	} else if err != nil {
 		a.logger.Error("%s", err)
 		httputil.WriteJSON(rw, newMessage(errUnhandledError), http.StatusInternalServerError)
-		return
+		return false
 	}
 
 	// Set request time boundaries and range
@@ -169,19 +333,19 @@ func (a *API) seriesPoints(rw http.ResponseWriter, r *http.Request) {
 			if req.StartTime, err = timerange.Apply(req.Time, req.Range); err != nil {
 				a.logger.Warning("unable to apply time range: %s", err)
 				httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
-				return
+				return false
 			}
 		} else {
 			req.StartTime = req.Time
 			if req.EndTime, err = timerange.Apply(req.Time, req.Range); err != nil {
 				a.logger.Warning("unable to apply time range: %s", err)
 				httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
-				return
+				return false
 			}
 		}
 	} else if (req.StartTime.IsZero() || req.EndTime.IsZero()) || req.Range != "" {
 		httputil.WriteJSON(rw, newMessage(errInvalidTimerange), http.StatusBadRequest)
-		return
+		return false
 	}
 
 	// Set default point sample if none provided
@@ -189,27 +353,10 @@ func (a *API) seriesPoints(rw http.ResponseWriter, r *http.Request) {
 		req.Sample = series.DefaultSample
 	}
 
-	// Execute points request
-	points := series.Response{
-		Start:   req.StartTime.Format(time.RFC3339),
-		End:     req.EndTime.Format(time.RFC3339),
-		Series:  a.executeRequest(req, req.Normalize),
-		Options: req.Graph.Options,
-	}
-
-	// Set fallback title to graph name if none provided
-	if points.Options == nil {
-		points.Options = make(map[string]interface{})
-	}
-
-	if _, ok := points.Options["title"]; !ok {
-		points.Options["title"] = req.Graph.Name
-	}
-
-	httputil.WriteJSON(rw, points, http.StatusOK)
+	return true
 }
 
-func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.ResponseSeries {
+func (a *API) executeRequest(ctx context.Context, req *series.Request, forceNormalize bool) []series.ResponseSeries {
 	// Expand groups series
 	for _, group := range req.Graph.Groups {
 		expandedSeries := []*storage.Series{}
@@ -220,40 +367,41 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 	}
 
 	// Dispatch point queries among providers
-	dataLen := 0
 	data := make([][]series.Series, len(req.Graph.Groups))
+	statuses := make([][]series.Status, len(req.Graph.Groups))
 	for i, group := range req.Graph.Groups {
 		seriesLen := len(group.Series)
 
-		dataLen += seriesLen
 		data[i] = make([]series.Series, seriesLen)
+		statuses[i] = make([]series.Status, seriesLen)
 	}
 
-	for _, q := range a.dispatchQueries(req) {
-		points, err := q.connector.Points(&q.query)
-		if err != nil {
-			a.logger.Error("unable to fetch points: %s", err)
-			continue
-		}
+	a.fetchPoints(ctx, a.dispatchQueries(req, statuses), data, statuses)
 
-		count := len(points)
-		expected := len(q.query.Metrics)
-		if count != expected {
-			a.logger.Error("unable to fetch points: expected %d series but got %d", expected, count)
+	// Derive time-shifted series names (expression groups series keeping their original names for reference)
+	for _, group := range req.Graph.Groups {
+		if group.Operator == series.OperatorExpression {
 			continue
 		}
 
-		// Put back series to its original indexes
-		for i, p := range points {
-			data[q.queryMap[i][0]][q.queryMap[i][1]] = p
+		for _, s := range group.Series {
+			s.Name = shiftedName(s.Name, timeshift(s.Options, group.Options))
 		}
 	}
 
-	// Lower sample size if too few points available
+	// Lower sample size if too few points available, only counting points within the requested time span as series
+	// may have been fetched over an extended one (e.g. forecast history)
 	maxPoints := 0
 	for i, group := range req.Graph.Groups {
 		for j := range group.Series {
-			if n := len(data[i][j].Points); n > maxPoints {
+			n := 0
+			for _, p := range data[i][j].Points {
+				if !p.Time.Before(req.StartTime) && !p.Time.After(req.EndTime) {
+					n++
+				}
+			}
+
+			if n > maxPoints {
 				maxPoints = n
 			}
 		}
@@ -263,13 +411,49 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 		req.Sample = maxPoints
 	}
 
-	// Generate points series
-	gaps := make(map[int][]struct{})
-	result := []series.ResponseSeries{}
+	// Collect series names referenced by expressions, as they need to be normalized to be aligned
+	refs := make(map[string]bool)
+	for _, group := range req.Graph.Groups {
+		if group.Operator != series.OperatorExpression {
+			continue
+		}
+
+		if expr, err := series.ParseExpr(group.Expression); err == nil {
+			for _, name := range expr.Refs() {
+				refs[name] = true
+			}
+		}
+	}
+
+	percentiles := []float64{}
+	if slice, ok := req.Graph.Options["percentiles"].([]interface{}); ok {
+		for _, entry := range slice {
+			if val, ok := entry.(float64); ok {
+				percentiles = append(percentiles, val)
+			}
+		}
+	}
+
+	stats := []string{}
+	if slice, ok := req.Graph.Options["summary_stats"].([]interface{}); ok {
+		for _, entry := range slice {
+			if val, ok := entry.(string); ok {
+				stats = append(stats, val)
+			}
+		}
+	}
+
+	// Generate points series, keeping track of normalized null values for time steps gaps cleanup
+	seriesCount := 0
+	gaps := make(map[int]int)
+
+	results := make([][]series.ResponseSeries, len(req.Graph.Groups))
 	for i, group := range req.Graph.Groups {
 		var (
-			consolidate int
-			err         error
+			consolidate    int
+			consolidatePct float64
+			fill           string
+			err            error
 		)
 
 		// Skip processing if no data
@@ -284,15 +468,33 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 			}
 		}
 
-		// Skip normalization if operator is not set and not forced
-		if group.Operator == series.OperatorNone && !forceNormalize {
-			goto finalize
+		// Apply series then group transforms if any, their definitions having been checked while dispatching queries
+		for j, s := range group.Series {
+			transforms, _ := parseTransforms(s.Options, group.Options)
+			for _, t := range transforms {
+				data[i][j].Transform(t)
+			}
 		}
 
 		// Get group consolidation mode and group options
-		consolidate = series.ConsolidateAverage
-		if v, ok := group.Options["consolidate"].(int); ok {
-			consolidate = v
+		consolidate, consolidatePct = groupConsolidation(group)
+
+		// Keep top or bottom ranked series and compute forecasts if requested
+		if group.Operator == series.OperatorNone {
+			data[i], statuses[i] = a.selectSeries(req, group, data[i], statuses[i], consolidate,
+				consolidatePct)
+			data[i], statuses[i] = a.forecastSeries(req, group, data[i], statuses[i], consolidate,
+				consolidatePct)
+		}
+
+		seriesCount += len(data[i])
+
+		// Skip normalization if operator is not set and neither forced, referenced by an expression nor needed for
+		// filling null values
+		fill, _ = group.Options["fill"].(string)
+		if group.Operator == series.OperatorNone && !forceNormalize && !referenced(group, refs) &&
+			(fill == "" || fill == series.FillNull) {
+			goto finalize
 		}
 
 		if ok, _ := group.Options["zero_nulls"].(bool); ok {
@@ -302,36 +504,63 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 		}
 
 		// Normalize series and apply operations
-		data[i], err = series.Normalize(data[i], req.StartTime, req.EndTime, req.Sample, consolidate)
+		data[i], err = series.Normalize(data[i], req.StartTime, req.EndTime, req.Sample, consolidate,
+			consolidatePct, fill)
 		if err != nil {
 			a.logger.Error("failed to normalize series: %s", err)
 			continue
 		}
 
 		// Keep reference of null values for future gaps cleanup
-		for _, series := range data[i] {
-			for idx, point := range series.Points {
-				if point.Value.IsNaN() {
-					_, ok := gaps[idx]
-					if !ok {
-						gaps[idx] = []struct{}{}
-					}
-					gaps[idx] = append(gaps[idx], struct{}{})
+		for _, s := range data[i] {
+			if len(s.Points) != req.Sample {
+				continue
+			}
+
+			for idx, p := range s.Points {
+				if p.Value.IsNaN() {
+					gaps[idx]++
 				}
 			}
 		}
 
 		switch group.Operator {
-		case series.OperatorAverage, series.OperatorSum:
+		case series.OperatorAverage, series.OperatorSum, series.OperatorMin, series.OperatorMax,
+			series.OperatorMedian, series.OperatorPercentile, series.OperatorCount, series.OperatorStddev:
 			var (
 				s   series.Series
 				err error
 			)
 
-			if group.Operator == series.OperatorAverage {
+			switch group.Operator {
+			case series.OperatorAverage:
 				s, err = series.Average(data[i])
-			} else {
+
+			case series.OperatorSum:
 				s, err = series.Sum(data[i])
+
+			case series.OperatorMin:
+				s, err = series.Min(data[i])
+
+			case series.OperatorMax:
+				s, err = series.Max(data[i])
+
+			case series.OperatorMedian:
+				s, err = series.Median(data[i])
+
+			case series.OperatorPercentile:
+				pct := defaultPercentile
+				if v, ok := group.Options["percentile"].(float64); ok && v > 0 && v <= 100 {
+					pct = v
+				}
+
+				s, err = series.Percentile(data[i], pct)
+
+			case series.OperatorCount:
+				s, err = series.Count(data[i])
+
+			case series.OperatorStddev:
+				s, err = series.Stddev(data[i])
 			}
 
 			if err != nil {
@@ -340,7 +569,7 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 			}
 
 			// Set series name to group name
-			group.Series[0].Name = group.Name
+			group.Series[0].Name = shiftedName(group.Name, timeshift(group.Options))
 
 			// Replace group series with operation result
 			data[i] = []series.Series{s}
@@ -348,6 +577,10 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 		case series.OperatorNone:
 			// noop
 
+		case series.OperatorExpression:
+			// Expressions are evaluated once all other groups have been processed
+			continue
+
 		default:
 			a.logger.Warning("unknown %d operation type", group.Operator)
 			continue
@@ -364,29 +597,54 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 			}
 
 			// Summarize series
-			percentiles := []float64{}
-			if slice, ok := req.Graph.Options["percentiles"].([]interface{}); ok {
-				for _, entry := range slice {
-					if val, ok := entry.(float64); ok {
-						percentiles = append(percentiles, val)
-					}
-				}
-			}
+			s.Summarize(percentiles, stats)
 
-			s.Summarize(percentiles)
+			// Merge group series statuses if operation has been applied
+			status := series.MergeStatus(statuses[i])
+			if group.Operator == series.OperatorNone {
+				status = statuses[i][j]
+			}
 
-			result = append(result, series.ResponseSeries{
+			results[i] = append(results[i], series.ResponseSeries{
 				Series:  s,
 				Name:    group.Series[j].Name,
 				Options: group.Series[j].Options,
+				Status:  status,
 			})
 		}
 	}
 
+	// Evaluate expression groups
+	for i, group := range req.Graph.Groups {
+		if group.Operator != series.OperatorExpression {
+			continue
+		}
+
+		s, status := a.evalExpression(group, data[i], statuses[i], results)
+
+		if scale, _ := group.Options["scale"].(float64); scale != 0 {
+			s.Scale(series.Value(scale))
+		}
+
+		s.Summarize(percentiles, stats)
+
+		results[i] = []series.ResponseSeries{{
+			Series:  s,
+			Name:    shiftedName(group.Name, timeshift(group.Options)),
+			Options: group.Options,
+			Status:  status,
+		}}
+	}
+
+	result := []series.ResponseSeries{}
+	for i := range results {
+		result = append(result, results[i]...)
+	}
+
 	// Cleanup gaps being present at the same position in all series
 	indexes := []int{}
-	for idx := range gaps {
-		if len(gaps[idx]) == dataLen {
+	for idx, count := range gaps {
+		if count == seriesCount {
 			indexes = append(indexes, idx)
 		}
 	}
@@ -397,53 +655,364 @@ func (a *API) executeRequest(req *series.Request, forceNormalize bool) []series.
 
 	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
 
-	for _, series := range result {
+	for i := range result {
+		if len(result[i].Points) != req.Sample {
+			continue
+		}
+
 		for _, idx := range indexes {
-			if idx < len(series.Points) { // FIXME: find why index goes beyond points length
-				series.Points = append(series.Points[:idx], series.Points[idx+1:]...)
-			}
+			result[i].Points = append(result[i].Points[:idx], result[i].Points[idx+1:]...)
 		}
 	}
 
 	return result
 }
 
-func (a *API) dispatchQueries(req *series.Request) []pointQuery {
+// evalExpression evaluates an expression group, referenced series being either the group normalized series or the
+// resulting series of other groups.
+func (a *API) evalExpression(group *storage.SeriesGroup, data []series.Series, statuses []series.Status,
+	results [][]series.ResponseSeries) (series.Series, series.Status) {
+	invalid := func(err error) (series.Series, series.Status) {
+		a.logger.Warning("unable to evaluate %q expression: %s", group.Expression, err)
+		return series.Series{Points: []series.Point{}}, series.Status{Code: series.StatusInvalid, Message: err.Error()}
+	}
+
+	expr, err := series.ParseExpr(group.Expression)
+	if err != nil {
+		return invalid(fmt.Errorf("invalid expression: %s", err))
+	}
+
+	// Build expression scope, group series taking precedence over other groups ones
+	scope := make(map[string]series.Series)
+	scopeStatuses := make(map[string]series.Status)
+
+	for i := range results {
+		for _, s := range results[i] {
+			scope[s.Name] = s.Series
+			scopeStatuses[s.Name] = s.Status
+		}
+	}
+
+	for j, s := range group.Series {
+		if j < len(data) {
+			scope[s.Name] = data[j]
+			scopeStatuses[s.Name] = statuses[j]
+		}
+	}
+
+	s, err := expr.Eval(scope)
+	if err != nil {
+		return invalid(err)
+	}
+
+	// Merge referenced series statuses
+	refStatuses := []series.Status{}
+	for _, name := range expr.Refs() {
+		refStatuses = append(refStatuses, scopeStatuses[name])
+	}
+
+	return s, series.MergeStatus(refStatuses)
+}
+
+// referenced returns whether or not a group has series referenced by expressions.
+func referenced(group *storage.SeriesGroup, refs map[string]bool) bool {
+	for _, s := range group.Series {
+		if refs[s.Name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectSeries keeps the top or bottom ranked series of a group as requested by its options, optionally folding the
+// remaining series into a single "other" series.
+func (a *API) selectSeries(req *series.Request, group *storage.SeriesGroup, data []series.Series,
+	statuses []series.Status, consolidate int, consolidatePct float64) ([]series.Series, []series.Status) {
+	var (
+		n         int
+		ascending bool
+	)
+
+	if v, ok := group.Options["top"].(float64); ok && v > 0 {
+		n = int(v)
+	} else if v, ok := group.Options["bottom"].(float64); ok && v > 0 {
+		n, ascending = int(v), true
+	}
+
+	if n == 0 || n >= len(data) {
+		return data, statuses
+	}
+
+	rankBy, _ := group.Options["rank_by"].(string)
+	if rankBy == "" {
+		rankBy = "avg"
+	}
+
+	// Only rank points within the requested time span (e.g. excluding forecast training history)
+	ranked := make([]series.Series, len(data))
+	for i := range data {
+		ranked[i] = trimSeries(data[i], req.StartTime, req.EndTime)
+	}
+
+	ranks, err := series.Rank(ranked, rankBy, ascending)
+	if err != nil {
+		a.logger.Warning("unable to rank series: %s", err)
+		return data, statuses
+	}
+
+	groupSeries := make([]*storage.Series, n)
+	selectedData := make([]series.Series, n)
+	selectedStatuses := make([]series.Status, n)
+
+	for i, idx := range ranks[:n] {
+		groupSeries[i] = group.Series[idx]
+		selectedData[i] = data[idx]
+		selectedStatuses[i] = statuses[idx]
+	}
+
+	// Fold remaining series into an "other" series if requested
+	if other, _ := group.Options["other"].(bool); other {
+		otherData := []series.Series{}
+		otherStatuses := []series.Status{}
+
+		for _, idx := range ranks[n:] {
+			otherData = append(otherData, data[idx])
+			otherStatuses = append(otherStatuses, statuses[idx])
+		}
+
+		otherData, err = series.Normalize(otherData, req.StartTime, req.EndTime, req.Sample, consolidate,
+			consolidatePct, series.FillNull)
+		if err == nil {
+			var s series.Series
+
+			if s, err = series.Sum(otherData); err == nil {
+				groupSeries = append(groupSeries, &storage.Series{Name: "other"})
+				selectedData = append(selectedData, s)
+				selectedStatuses = append(selectedStatuses, series.MergeStatus(otherStatuses))
+			}
+		}
+
+		if err != nil {
+			a.logger.Error("failed to compute other series: %s", err)
+		}
+	}
+
+	group.Series = groupSeries
+
+	return selectedData, selectedStatuses
+}
+
+// groupConsolidation returns a group consolidation mode and percentile value, the consolidation mode being either set
+// using the group field or the legacy "consolidate" group option.
+func groupConsolidation(group *storage.SeriesGroup) (int, float64) {
+	consolidate := group.Consolidate
+	if consolidate == 0 {
+		if v, ok := group.Options["consolidate"].(float64); ok {
+			consolidate = int(v)
+		} else {
+			consolidate = series.ConsolidateAverage
+		}
+	}
+
+	pct := defaultPercentile
+	if v, ok := group.Options["consolidate_percentile"].(float64); ok && v > 0 && v <= 100 {
+		pct = v
+	}
+
+	return consolidate, pct
+}
+
+// timeshift returns the first time shift value found in a list of series or group options.
+func timeshift(options ...map[string]interface{}) string {
+	for _, o := range options {
+		if v, ok := o["timeshift"].(string); ok && v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// shiftedName returns a series name suffixed with its time shift value if any.
+func shiftedName(name, shift string) string {
+	if shift == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s (%s)", name, shift)
+}
+
+// parseTransforms returns the transforms list defined in series then group options.
+func parseTransforms(options ...map[string]interface{}) ([]series.Transform, error) {
+	result := []series.Transform{}
+
+	for _, opts := range options {
+		v, ok := opts["transforms"]
+		if !ok {
+			continue
+		}
+
+		transforms, err := series.ParseTransforms(v)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, transforms...)
+	}
+
+	return result, nil
+}
+
+// fetchPoints executes point queries concurrently, filling data and statuses slices at the original series indexes.
+func (a *API) fetchPoints(ctx context.Context, queries []pointQuery, data [][]series.Series,
+	statuses [][]series.Status) {
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, a.config.Series.MaxParallelQueries)
+
+	for _, q := range queries {
+		wg.Add(1)
+
+		go func(q pointQuery) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Apply per-provider timeout if any
+			queryCtx := ctx
+			if a.config.Series.ProviderTimeout > 0 {
+				var cancel context.CancelFunc
+
+				queryCtx, cancel = context.WithTimeout(ctx, time.Duration(a.config.Series.ProviderTimeout)*time.Second)
+				defer cancel()
+			}
+
+			points, err := q.connector.Points(queryCtx, &q.query)
+			if err == nil && len(points) != len(q.query.Metrics) {
+				err = fmt.Errorf("expected %d series but got %d", len(q.query.Metrics), len(points))
+			} else if err != nil && queryCtx.Err() == context.DeadlineExceeded {
+				err = fmt.Errorf("timed out")
+			}
+
+			if err != nil {
+				a.logger.Error("unable to fetch points from %q provider: %s", q.connector.Name(), err)
+
+				for _, idx := range q.queryMap {
+					statuses[idx[0]][idx[1]] = series.Status{
+						Code:    series.StatusProviderError,
+						Message: fmt.Sprintf("%s: %s", q.connector.Name(), err),
+					}
+				}
+
+				return
+			}
+
+			// Put back series to its original indexes, re-aligning time-shifted points onto the requested time span
+			for i, p := range points {
+				if q.offset != 0 {
+					p.Shift(q.offset)
+				}
+
+				data[q.queryMap[i][0]][q.queryMap[i][1]] = p
+			}
+		}(q)
+	}
+
+	wg.Wait()
+}
+
+func (a *API) dispatchQueries(req *series.Request, statuses [][]series.Status) []pointQuery {
 	providers := make(map[string]*pointQuery)
 
 	for i, group := range req.Graph.Groups {
 		for j, s := range group.Series {
 			if !s.IsValid() {
 				a.logger.Warning("invalid series metric: %s", s)
+				statuses[i][j] = series.Status{Code: series.StatusInvalid}
 				continue
 			}
 
 			search := a.searcher.Metrics(s.Origin, s.Source, s.Metric)
 			if len(search) == 0 {
 				a.logger.Warning("unable to find series metric: %s", s)
+				statuses[i][j] = series.Status{Code: series.StatusNotInCatalog}
+				continue
+			}
+
+			// Apply series time shift if any
+			startTime, endTime := req.StartTime, req.EndTime
+
+			shift := timeshift(s.Options, group.Options)
+			if shift != "" {
+				var err error
+
+				startTime, err = timerange.Apply(req.StartTime, shift)
+				if err == nil {
+					endTime, err = timerange.Apply(req.EndTime, shift)
+				}
+
+				if err != nil {
+					a.logger.Warning("invalid series time shift: %s", shift)
+					statuses[i][j] = series.Status{
+						Code:    series.StatusInvalid,
+						Message: fmt.Sprintf("invalid timeshift %q", shift),
+					}
+					continue
+				}
+			}
+
+			if _, err := parseTransforms(s.Options, group.Options); err != nil {
+				a.logger.Warning("unable to parse transforms: %s", err)
+				statuses[i][j] = series.Status{
+					Code:    series.StatusInvalid,
+					Message: err.Error(),
+				}
 				continue
 			}
 
+			// Extend query time span to fetch forecast training history if any
+			offset, sample, history := req.StartTime.Sub(startTime), req.Sample, ""
+
+			if fo := seriesForecast(s, group, req.Graph.Options); fo != nil {
+				historyTime, err := timerange.Apply(startTime, fo.history)
+				if err != nil || !historyTime.Before(startTime) {
+					a.logger.Warning("invalid series forecast history: %s", fo.history)
+					statuses[i][j] = series.Status{
+						Code:    series.StatusInvalid,
+						Message: fmt.Sprintf("invalid forecast history %q", fo.history),
+					}
+					continue
+				}
+
+				sample = int(float64(req.Sample) * float64(endTime.Sub(historyTime)) / float64(endTime.Sub(startTime)))
+				startTime, history = historyTime, fo.history
+			}
+
+			statuses[i][j] = series.Status{Code: series.StatusOK}
+
 			// Get series connector and provider name
 			c := search[0].Catalog().Connector.(connector.Connector)
 			provName := c.Name()
 
-			// Initialize provider-specific point query
-			if _, ok := providers[provName]; !ok {
-				providers[provName] = &pointQuery{
+			// Initialize provider-specific point query (one per distinct time shift and forecast history)
+			key := provName + "\x00" + shift + "\x00" + history
+			if _, ok := providers[key]; !ok {
+				providers[key] = &pointQuery{
 					query: series.Query{
-						StartTime: req.StartTime,
-						EndTime:   req.EndTime,
-						Sample:    req.Sample,
+						StartTime: startTime,
+						EndTime:   endTime,
+						Sample:    sample,
 					},
 					queryMap:  [][2]int{},
 					connector: c,
+					offset:    offset,
 				}
 			}
 
 			// Append new series to point query and save series index
-			providers[provName].query.Metrics = append(providers[provName].query.Metrics, search[0])
-			providers[provName].queryMap = append(providers[provName].queryMap, [2]int{i, j})
+			providers[key].query.Metrics = append(providers[key].query.Metrics, search[0])
+			providers[key].queryMap = append(providers[key].queryMap, [2]int{i, j})
 		}
 	}
 
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"facette.io/facette/series"
+	"facette.io/httputil"
+)
+
+// api:method POST /api/v1/series/stream "Stream series data points"
+//
+// This endpoint streams data points for all of a graph's series as Server-Sent Events, based on the same query as the
+// `/api/v1/series/points` endpoint along with the following optional element:
+//
+//   * `interval` (type _string_, default `"10s"`): refresh interval (minimum: `1s`)
+//
+// A `points` event is first sent, holding the same response as the `/api/v1/series/points` endpoint. Then, at each
+// refresh interval, the requested time span is slid up to the current time and an `update` event is sent, having
+// for each series only the points starting from the last one previously sent (its value possibly having been
+// updated meanwhile) along with updated summaries. Series are to be matched by position in the `series` array. If
+// the list of series changes, a new `points` event holding all of the points is sent instead.
+//
+// Time span bounds are aligned on the sampling step (rounded up to the second) so that points timestamps remain
+// stable across updates.
+//
+// The stream ends when the client disconnects.
+//
+// ---
+// section: series
+// request:
+//   type: object
+//   examples:
+//   - format: javascript
+//     headers:
+//       Content-Type: application/json
+//     body: |
+//       {
+//         "id": "c5e5faf1-dda1-50b3-abcb-4a5bdae7328e",
+//         "range": "-1h",
+//         "interval": "30s"
+//       }
+// responses:
+//   200:
+//     type: string
+//     examples:
+//     - format: text
+//       headers:
+//         Content-Type: text/event-stream
+//       body: |
+//         event: points
+//         data: {"start":"2017-06-07T11:29:08Z","end":"2017-06-07T12:29:08Z","series":[...],...}
+//
+//         event: update
+//         data: {"start":"2017-06-07T11:29:38Z","end":"2017-06-07T12:29:38Z","series":[...],...}
+func (a *API) seriesStream(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	defer r.Body.Close()
+
+	// Get stream request from received data
+	req := &series.StreamRequest{}
+	if err = httputil.BindJSON(r, req); err == httputil.ErrInvalidContentType {
+		httputil.WriteJSON(rw, newMessage(err), http.StatusUnsupportedMediaType)
+		return
+	} else if err != nil {
+		a.logger.Error("unable to unmarshal JSON data: %s", err)
+		httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+		return
+	}
+
+	interval := series.DefaultStreamInterval
+	if req.Interval != "" {
+		if interval, err = time.ParseDuration(req.Interval); err != nil || interval < series.MinStreamInterval {
+			httputil.WriteJSON(rw, newMessage(errInvalidParameter), http.StatusBadRequest)
+			return
+		}
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		a.logger.Error("response writer doesn't support flushing")
+		httputil.WriteJSON(rw, newMessage(errUnhandledError), http.StatusInternalServerError)
+		return
+	}
+
+	if !a.prepareRequest(rw, &req.Request) {
+		return
+	}
+
+	// Keep pristine graph, sample and time span as executing requests alters them
+	graph := req.Graph.Clone()
+	sample := req.Sample
+	span := req.EndTime.Sub(req.StartTime)
+
+	// Align time span on sampling step
+	req.StartTime, req.EndTime = series.StreamWindow(req.EndTime, span, sample)
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+
+	send := func(event string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		if _, err = fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+
+		return nil
+	}
+
+	points := a.pointsResponse(r.Context(), &req.Request)
+
+	if err = send("points", points); err != nil {
+		a.logger.Error("failed to send points: %s", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-ticker.C:
+			// Slide time span up to current time
+			startTime, endTime := series.StreamWindow(time.Now().UTC(), span, sample)
+
+			update := a.pointsResponse(r.Context(), &series.Request{
+				StartTime: startTime,
+				EndTime:   endTime,
+				Sample:    sample,
+				Graph:     graph.Clone(),
+				Normalize: req.Normalize,
+			})
+
+			// Stop silently if client disconnected while executing request
+			if r.Context().Err() != nil {
+				return
+			}
+
+			// Send all points if series changed since previous response
+			if delta, ok := update.Delta(points); ok {
+				err = send("update", delta)
+			} else {
+				err = send("points", update)
+			}
+
+			if err != nil {
+				a.logger.Error("failed to send points update: %s", err)
+				return
+			}
+
+			points = update
+		}
+	}
+}
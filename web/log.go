@@ -25,6 +25,13 @@ func (rw responseWriter) WriteHeader(status int) {
 	)
 }
 
+// Flush implements the http.Flusher interface, allowing streaming responses.
+func (rw responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func (h *Handler) handleLog(hh http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 		hh.ServeHTTP(responseWriter{rw, r, h.logger}, r)